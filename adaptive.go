@@ -0,0 +1,57 @@
+package wikipage
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// adaptiveSteps is the number of healthy replies the adaptive rate takes to climb back from its minimum to its maximum.
+const adaptiveSteps = 50
+
+// adaptiveRate tunes the rate of its limiter between min and max, additive increase multiplicative decrease:
+// it halves the rate on every throttled reply and raises it by a step on every healthy one.
+type adaptiveRate struct {
+	mu       sync.Mutex
+	limiter  *rate.Limiter
+	min, max rate.Limit
+}
+
+func newAdaptiveRate(min, max rate.Limit) *adaptiveRate {
+	if min <= 0 {
+		min = max / adaptiveSteps
+	}
+	if max < min {
+		max = min
+	}
+	return &adaptiveRate{limiter: rate.NewLimiter(max, DefaultBurst), min: min, max: max}
+}
+
+// Record adjusts the rate after a reply with the specified status and header.
+func (a *adaptiveRate) Record(status int, header http.Header) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	limit := a.limiter.Limit()
+	if throttled(status, header) {
+		limit /= 2
+	} else {
+		limit += (a.max - a.min) / adaptiveSteps
+	}
+	switch {
+	case limit < a.min:
+		limit = a.min
+	case limit > a.max:
+		limit = a.max
+	}
+	a.limiter.SetLimit(limit)
+}
+
+// throttled checks if a reply asks to slow down: too many requests, service unavailable or, for the action API, replication lag over maxlag.
+func throttled(status int, header http.Header) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable || header.Get("MediaWiki-API-Error") == "maxlag"
+}
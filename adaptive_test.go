@@ -0,0 +1,65 @@
+package wikipage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestAdaptiveRate(t *testing.T) {
+	var throttling int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case atomic.LoadInt32(&throttling) == 0:
+			w.Write([]byte(`{}`))
+		case r.URL.Query().Get("maxlag") != "":
+			w.Header().Set("MediaWiki-API-Error", "maxlag")
+			w.Write([]byte(`{"errors":[{"code":"maxlag","text":"Waiting for a database server"}]}`))
+		default:
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithAdaptiveRate(10, 1000))
+	if rh.limiter == New("mytest").limiter {
+		t.Fatal("WithAdaptiveRate shouldn't tune the limiter shared with the other handlers")
+	}
+	if limit := rh.limiter.Limit(); limit != 1000 {
+		t.Error("The adaptive rate should start at the maximum, instead it's", limit)
+	}
+
+	ctx := context.Background()
+	for i, expected := range []rate.Limit{500, 250, 125} {
+		query := server.URL
+		if i == 2 {
+			query += "?maxlag=5"
+		}
+		rh.fetch(ctx, query)
+		if limit := rh.limiter.Limit(); limit != expected {
+			t.Error("After", i+1, "throttled replies the rate should be", expected, "instead it's", limit)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		rh.fetch(ctx, server.URL)
+	}
+	if limit := rh.limiter.Limit(); limit != 10 {
+		t.Error("The adaptive rate shouldn't drop below the minimum, instead it's", limit)
+	}
+
+	atomic.StoreInt32(&throttling, 0)
+	rh.fetch(ctx, server.URL)
+	if limit := rh.limiter.Limit(); limit != 10+rate.Limit(990)/adaptiveSteps {
+		t.Error("After a healthy reply the rate should rise by a step, instead it's", limit)
+	}
+	for i := 0; i < 2*adaptiveSteps; i++ {
+		rh.fetch(ctx, server.URL)
+	}
+	if limit := rh.limiter.Limit(); limit != 1000 {
+		t.Error("The adaptive rate shouldn't rise over the maximum, instead it's", limit)
+	}
+}
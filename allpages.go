@@ -0,0 +1,50 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// AllPages streams the titles of all the articles (main namespace, redirects excluded) of the wiki, querying them in chunks through the action API.
+// Titles are streamed as they are retrieved, so memory usage doesn't depend on the size of the wiki. Titles must be drained until the channel is closed,
+// or ctx cancelled; afterwards the error channel yields the error, if any, and is closed as well.
+func (rh RequestHandler) AllPages(ctx context.Context) (<-chan string, <-chan error) {
+	titles, errs := make(chan string, 64), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(titles)
+
+		params := url.Values{
+			"action":        {"query"},
+			"list":          {"allpages"},
+			"apnamespace":   {"0"},
+			"apfilterredir": {"nonredirects"},
+			"aplimit":       {"max"},
+		}
+		err := rh.queryAll(ctx, params, func(body []byte) error {
+			var data struct {
+				Query struct {
+					Allpages []struct{ Title string }
+				}
+			}
+			if err := json.Unmarshal(body, &data); err != nil {
+				return err
+			}
+
+			for _, p := range data.Query.Allpages {
+				select {
+				case titles <- p.Title:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return titles, errs
+}
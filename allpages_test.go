@@ -0,0 +1,48 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAllPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("apcontinue") {
+		case "":
+			fmt.Fprint(w, `{"continue":{"apcontinue":"Autism","continue":"-||"},"query":{"allpages":[{"pageid":12,"ns":0,"title":"Anarchism"},{"pageid":39,"ns":0,"title":"Albedo"}]}}`)
+		case "Autism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"allpages":[{"pageid":25,"ns":0,"title":"Autism"}]}}`)
+		default:
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	titles, errs := rh.AllPages(context.Background())
+	var result []string
+	for title := range titles {
+		result = append(result, title)
+	}
+	if err := <-errs; err != nil {
+		t.Error("AllPages returns", err)
+	}
+	if expected := []string{"Anarchism", "Albedo", "Autism"}; !reflect.DeepEqual(result, expected) {
+		t.Error("AllPages returns", result, "expected", expected)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	titles, errs = rh.AllPages(ctx)
+	for range titles {
+	}
+	if err := <-errs; err == nil {
+		t.Error("AllPages should stop when the context is cancelled")
+	}
+}
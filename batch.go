@@ -0,0 +1,275 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// batchSize is the maximum number of pages the action API accepts in a single query.
+const batchSize = 50
+
+// FromIDs returns the WikiPages with the specified IDs, issuing a single query for every batch of 50 IDs.
+// Pages that couldn't be retrieved, missing ones included, are reported in the error map.
+func (rh RequestHandler) FromIDs(ctx context.Context, IDs []PageID) (ID2Page map[PageID]WikiPage, ID2Error map[PageID]error) {
+	ID2Page, ID2Error = make(map[PageID]WikiPage, len(IDs)), map[PageID]error{}
+	for len(IDs) > 0 {
+		batch := IDs
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		IDs = IDs[len(batch):]
+
+		if err := rh.idsFrom(ctx, batch, ID2Page); err != nil {
+			for _, ID := range batch {
+				ID2Error[ID] = err
+			}
+		}
+	}
+
+	for ID, p := range ID2Page {
+		if p.ID == 0 {
+			delete(ID2Page, ID)
+			ID2Error[ID] = errors.WithStack(pageNotFound{id: ID, endpoint: "query"})
+		}
+	}
+	for ID := range ID2Error {
+		delete(ID2Page, ID)
+	}
+	return
+}
+
+// idsFrom queries for a batch of IDs, storing the results in ID2Page: missing pages are stored with zero ID.
+func (rh RequestHandler) idsFrom(ctx context.Context, IDs []PageID, ID2Page map[PageID]WikiPage) error {
+	sIDs := make([]string, len(IDs))
+	for i, ID := range IDs {
+		sIDs[i] = ID.String()
+		ID2Page[ID] = WikiPage{}
+	}
+
+	params := url.Values{
+		"action":      {"query"},
+		"prop":        {"extracts"},
+		"exintro":     {""},
+		"explaintext": {""},
+		"exchars":     {strconv.Itoa(extractChars)},
+		"exlimit":     {"max"},
+		"pageids":     {strings.Join(sIDs, "|")},
+	}
+	return rh.queryAll(ctx, params, func(body []byte) error {
+		var data struct {
+			Query struct {
+				Pages []mayMissingPage
+			}
+		}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return err
+		}
+
+		//Extracts may be spread across continuations
+		for _, p := range data.Query.Pages {
+			switch old, ok := ID2Page[p.ID]; {
+			case !ok, p.Missing:
+				//Do nothing
+			case old.ID != 0 && p.Abstract == "":
+				//Keep the extract from previous replies
+			default:
+				ID2Page[p.ID] = rh.finish(rh.localize(derive(p.page())))
+			}
+		}
+		return nil
+	})
+}
+
+// FromTitles returns the WikiPages with the specified titles, following redirects; handler defaults may be overridden for this call only through options.
+// Unless extras provided by the REST API are requested through Include, outside compact mode, it issues a single query to the fall back API for every batch of 50 titles:
+// titles resolving to the same article, through normalization or redirects (e.g. "USA" and "U.S.A."), are retrieved once and mapped
+// to the same WikiPage, apart from RequestedTitle and NormalizedTitle which always refer to the original title.
+// Otherwise, for richer and consistent data, every title is retrieved as From does, 50 at a time concurrently: at the cost of a request for every title.
+// Pages that couldn't be retrieved, missing ones included, are reported in the error map.
+func (rh RequestHandler) FromTitles(ctx context.Context, titles []string, options ...CallOption) (title2Page map[string]WikiPage, title2Error map[string]error) {
+	title2Page, title2Error = make(map[string]WikiPage, len(titles)), map[string]error{}
+	rh, c := rh.with(options...)
+	summaries := !c.compact && c.include&^fallbackOnly != 0
+
+	//Duplicate titles are queried once
+	var unique []string
+	for _, title := range titles {
+		if _, ok := title2Page[title]; !ok {
+			title2Page[title] = WikiPage{}
+			unique = append(unique, title)
+		}
+	}
+
+	for len(unique) > 0 {
+		batch := unique
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		unique = unique[len(batch):]
+
+		if summaries {
+			rh.summariesFrom(ctx, batch, title2Page, title2Error, options)
+		} else if err := rh.titlesFrom(ctx, batch, title2Page, title2Error); err != nil {
+			for _, title := range batch {
+				title2Error[title] = err
+			}
+		}
+	}
+
+	for title, p := range title2Page {
+		if _, failed := title2Error[title]; p.ID == 0 && !failed {
+			delete(title2Page, title)
+			title2Error[title] = errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+		}
+	}
+	for title := range title2Error {
+		delete(title2Page, title)
+	}
+	return
+}
+
+// summariesFrom retrieves a batch of titles concurrently as From does, storing the results in title2Page and the errors in title2Error.
+func (rh RequestHandler) summariesFrom(ctx context.Context, titles []string, title2Page map[string]WikiPage, title2Error map[string]error, options []CallOption) {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, title := range titles {
+		wg.Add(1)
+		go func(title string) {
+			defer wg.Done()
+			p, err := rh.From(ctx, title, options...)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				title2Error[title] = err
+			} else {
+				title2Page[title] = p
+			}
+		}(title)
+	}
+	wg.Wait()
+}
+
+// titlesFrom queries for a batch of titles, storing the results in title2Page and interwiki titles in title2Error: missing pages are stored with zero ID.
+func (rh RequestHandler) titlesFrom(ctx context.Context, titles []string, title2Page map[string]WikiPage, title2Error map[string]error) error {
+	extraProps, extraParams := rh.include.fallbackParams()
+	params, err := url.ParseQuery(strings.TrimPrefix(extraParams, "&"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for key, value := range map[string]string{
+		"action":      "query",
+		"prop":        "extracts" + extraProps,
+		"exintro":     "",
+		"explaintext": "",
+		"exchars":     strconv.Itoa(extractChars),
+		"exlimit":     "max",
+		"redirects":   "",
+		"titles":      strings.Join(titles, "|"),
+	} {
+		params.Set(key, value)
+	}
+	if rh.compact { //Metadata only
+		params.Set("prop", strings.TrimPrefix(extraProps, "|"))
+		for _, key := range []string{"exintro", "explaintext", "exchars", "exlimit"} {
+			params.Del(key)
+		}
+	}
+
+	normalized, redirects, resolved, interwiki := map[string]string{}, map[string]string{}, map[string]WikiPage{}, map[string]InterwikiTitle{}
+	err = rh.queryAll(ctx, params, func(body []byte) error {
+		var data struct {
+			Query struct {
+				Normalized, Redirects []struct{ From, To string }
+				Interwiki             []struct{ Title, IW string }
+				Pages                 []mayMissingPage
+			}
+		}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return err
+		}
+
+		for _, n := range data.Query.Normalized {
+			normalized[n.From] = n.To
+		}
+		for _, r := range data.Query.Redirects {
+			redirects[r.From] = r.To
+		}
+		for _, iw := range data.Query.Interwiki {
+			interwiki[iw.Title] = InterwikiTitle{iw.IW, strings.TrimPrefix(iw.Title, iw.IW+":")}
+		}
+		//Extracts may be spread across continuations
+		for _, p := range data.Query.Pages {
+			switch old := resolved[p.Title]; {
+			case p.Missing:
+				//Do nothing
+			case old.ID != 0 && p.Abstract == "":
+				//Keep the extract from previous replies
+				if old.Length == 0 {
+					old.Length = p.Length
+					resolved[p.Title] = old
+				}
+			default:
+				page := p.page()
+				if page.Length == 0 {
+					page.Length = old.Length
+				}
+				resolved[p.Title] = rh.finish(rh.compacted(rh.include.keep(rh.localize(derive(page)))))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	//Map every requested title back to the page it resolves to
+	for _, title := range titles {
+		normalizedTitle := title
+		if to, ok := normalized[title]; ok {
+			normalizedTitle = to
+		}
+		if iw, ok := interwiki[normalizedTitle]; ok {
+			title2Error[title] = errors.WithStack(iw)
+			continue
+		}
+		target := normalizedTitle
+		for hops := 0; hops < maxRedirectHops; hops++ {
+			to, ok := redirects[target]
+			if !ok {
+				break
+			}
+			target = to
+		}
+
+		if p := resolved[target]; p.ID != 0 {
+			p.RequestedTitle, p.NormalizedTitle = title, normalizedTitle
+			title2Page[title] = p
+		}
+	}
+	return nil
+}
+
+// Result is the outcome of the retrieval of a page in a batch.
+type Result struct {
+	Title string
+	Page  WikiPage
+	Err   error
+}
+
+// FromTitlesOrdered is like FromTitles, but it returns the results in a slice aligned to titles, duplicates included, e.g. to output them in the input order.
+// Every page that couldn't be retrieved has its own error in the results, the returned error reports only the end of the context.
+func (rh RequestHandler) FromTitlesOrdered(ctx context.Context, titles []string, options ...CallOption) ([]Result, error) {
+	title2Page, title2Error := rh.FromTitles(ctx, titles, options...)
+	results := make([]Result, len(titles))
+	for i, title := range titles {
+		results[i] = Result{title, title2Page[title], title2Error[title]}
+	}
+	return results, ctx.Err()
+}
@@ -0,0 +1,195 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// batchServer serves generated pages to batched queries, spreading extracts across continuations as the API does.
+func batchServer(t *testing.T) *httptest.Server {
+	const exlimit = 20
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		sIDs := strings.Split(q.Get("pageids"), "|")
+		if len(sIDs) > batchSize {
+			t.Error("Batch too large", len(sIDs))
+		}
+		offset, _ := strconv.Atoi(q.Get("excontinue"))
+
+		var response struct {
+			Continue map[string]interface{} `json:"continue,omitempty"`
+			Query    struct {
+				Pages []mayMissingPage
+			}
+		}
+		for i, sID := range sIDs {
+			ID, _ := strconv.ParseUint(sID, 10, 32)
+			p, ok := generatePage(uint32(ID))
+			if !ok {
+				response.Query.Pages = append(response.Query.Pages, mayMissingPage{Missing: true, WikiPage: WikiPage{ID: PageID(ID)}})
+				continue
+			}
+			if i < offset || i >= offset+exlimit {
+				p.Abstract = ""
+			}
+			response.Query.Pages = append(response.Query.Pages, mayMissingPage{WikiPage: p})
+		}
+		if offset+exlimit < len(sIDs) {
+			response.Continue = map[string]interface{}{"excontinue": offset + exlimit, "continue": "||"}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestFromIDs(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	var IDs []PageID
+	for ID := PageID(1); ID < 120; ID++ {
+		IDs = append(IDs, ID)
+	}
+	ID2Page, ID2Error := rh.FromIDs(context.Background(), IDs)
+	for _, ID := range IDs {
+		expected, ok := generatePage(uint32(ID))
+		switch p, err := ID2Page[ID], ID2Error[ID]; {
+		case ok && err != nil:
+			t.Error("For", ID, "expected", expected, "got", err)
+		case ok && !reflect.DeepEqual(p, expected):
+			t.Error("For", ID, "expected", expected, "got", p)
+		case !ok:
+			if notFoundID, notFound := NotFoundID(err); !notFound || notFoundID != ID {
+				t.Error("For", ID, "expected a not found error, got", p, err)
+			}
+			if title, _ := NotFound(err); title != ID.String() {
+				t.Error("For", ID, "NotFound returns", title)
+			}
+		}
+	}
+}
+
+func TestFromTitles(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if titles := r.URL.Query().Get("titles"); titles != "USA|U.S.A.|united States|Atlantis" {
+			t.Error("Unexpected titles", titles)
+		}
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"normalized":[{"fromencoded":false,"from":"united States","to":"United States"}],"redirects":[{"from":"USA","to":"United States"},{"from":"U.S.A.","to":"United States"}],"pages":[{"ns":0,"title":"Atlantis","missing":true},{"pageid":3434750,"ns":0,"title":"United States","extract":"The United States of America is a country primarily located in North America."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	title2Page, title2Error := rh.FromTitles(context.Background(), []string{"USA", "U.S.A.", "united States", "USA", "Atlantis"})
+	if requests != 1 {
+		t.Error("FromTitles should issue a single request, issued", requests)
+	}
+	for title, normalizedTitle := range map[string]string{"USA": "USA", "U.S.A.": "U.S.A.", "united States": "United States"} {
+		switch p, err := title2Page[title], title2Error[title]; {
+		case err != nil:
+			t.Error("For", title, "got", err)
+		case p.ID != 3434750 || p.Title != "United States" || p.RequestedTitle != title || p.NormalizedTitle != normalizedTitle:
+			t.Error("For", title, "got", p)
+		}
+	}
+	if _, notFound := NotFound(title2Error["Atlantis"]); !notFound {
+		t.Error("For Atlantis expected a not found error, got", title2Error["Atlantis"])
+	}
+	if _, byID := NotFoundID(title2Error["Atlantis"]); byID {
+		t.Error("For Atlantis NotFoundID should fail")
+	}
+}
+
+func TestFromTitlesInterwiki(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"interwiki":[{"title":"fr:Anarchisme","iw":"fr"}],"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	title2Page, title2Error := rh.FromTitles(context.Background(), []string{"Anarchism", "fr:Anarchisme"})
+	if p, err := title2Page["Anarchism"], title2Error["Anarchism"]; p.ID != 12 || err != nil {
+		t.Error("For Anarchism got", p, err)
+	}
+	if iw, ok := errors.Cause(title2Error["fr:Anarchisme"]).(InterwikiTitle); !ok || iw != (InterwikiTitle{"fr", "Anarchisme"}) {
+		t.Error("For fr:Anarchisme expected an interwiki reference, got", title2Error["fr:Anarchisme"])
+	}
+}
+
+func TestFromTitlesExtras(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/w/api.php":
+			if prop := r.URL.Query().Get("prop"); prop != "extracts|info" {
+				t.Error("Unexpected prop", prop)
+			}
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","extract":"Rome is the capital city of Italy.","length":213475},{"pageid":46,"ns":0,"title":"Milan","extract":"Milan is a city in northern Italy.","length":154218}]}}`)
+		case strings.HasSuffix(r.URL.Path, "/Atlantis"):
+			http.Error(w, "Not Found", http.StatusNotFound)
+		default:
+			title := strings.TrimPrefix(r.URL.Path, "/api/rest_v1/page/summary/")
+			fmt.Fprintf(w, `{"type":"standard","title":"%v","pageid":%v,"namespace":{"id":0},"extract":"%v is a city in Italy.","description":"City in Italy"}`, title, len(title), title)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	title2Page, title2Error := rh.FromTitles(context.Background(), []string{"Rome", "Milan"}, Include(ExtraLength))
+	if len(title2Error) != 0 || title2Page["Rome"].Length != 213475 || title2Page["Milan"].Length != 154218 {
+		t.Error("FromTitles returns", title2Page, title2Error)
+	}
+
+	title2Page, title2Error = rh.FromTitles(context.Background(), []string{"Rome", "Milan", "Atlantis"}, Include(ExtraDescription))
+	for _, title := range []string{"Rome", "Milan"} {
+		if p := title2Page[title]; p.Title != title || p.Description != "City in Italy" || title2Error[title] != nil {
+			t.Error("For", title, "got", p, title2Error[title])
+		}
+	}
+	if details, ok := NotFoundDetailsOf(title2Error["Atlantis"]); !ok || details.Endpoint != "rest" {
+		t.Error("For Atlantis expected a not found error, got", title2Error["Atlantis"])
+	}
+}
+
+func TestFromTitlesOrdered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Atlantis","missing":true},{"pageid":46,"ns":0,"title":"Milan","extract":"Milan is a city in northern Italy."},{"pageid":45,"ns":0,"title":"Rome","extract":"Rome is the capital city of Italy."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	titles := []string{"Rome", "Atlantis", "Milan", "Rome"}
+	results, err := rh.FromTitlesOrdered(context.Background(), titles)
+	if err != nil || len(results) != len(titles) {
+		t.Fatal("FromTitlesOrdered returns", results, err)
+	}
+	for i, r := range results {
+		switch _, notFound := NotFound(r.Err); {
+		case r.Title != titles[i]:
+			t.Error("Result", i, "is for", r.Title, "expected", titles[i])
+		case r.Title == "Atlantis" && !notFound:
+			t.Error("Result", i, "expected a not found error, got", r)
+		case r.Title != "Atlantis" && (r.Err != nil || r.Page.Title != r.Title):
+			t.Error("Result", i, "is", r)
+		}
+	}
+}
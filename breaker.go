@@ -0,0 +1,71 @@
+package wikipage
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is the error returned, without querying the API, while the circuit breaker set through WithCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open after too many consecutive failures")
+
+// circuitBreaker stops requests after too many consecutive failures: once open, it lets a single probe request through every cooldown
+// (half open), which closes it on success.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	maxFailures int
+	cooldown    time.Duration
+	failures    int       //Consecutive failures
+	closedAt    time.Time //When the open breaker lets the next probe through
+	probing     bool      //A probe request is in flight
+}
+
+func newCircuitBreaker(maxFailures int, cooldown time.Duration) *circuitBreaker {
+	if maxFailures <= 0 {
+		return nil
+	}
+	return &circuitBreaker{maxFailures: maxFailures, cooldown: cooldown}
+}
+
+// Allow checks if a request may be issued at time now, otherwise it returns ErrCircuitOpen.
+func (b *circuitBreaker) Allow(now time.Time) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < b.maxFailures:
+		return nil
+	case b.probing || now.Before(b.closedAt):
+		return errors.WithStack(ErrCircuitOpen)
+	}
+	b.probing = true
+	return nil
+}
+
+// Record records at time now the outcome of an allowed request: transport errors, server errors and throttling are failures,
+// requests aborted through ctx don't count.
+func (b *circuitBreaker) Record(ctx context.Context, now time.Time, status int, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	switch {
+	case ctx.Err() != nil:
+		//Do nothing
+	case err != nil || status >= http.StatusInternalServerError || status == http.StatusTooManyRequests:
+		if b.failures++; b.failures >= b.maxFailures {
+			b.closedAt = now.Add(b.cooldown)
+		}
+	default:
+		b.failures = 0
+	}
+}
@@ -0,0 +1,59 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var requests, healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	rh := New("mytest", WithClock(clock), WithMaxAttempts(5), WithCircuitBreaker(3, time.Minute))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+
+	//Failures open the breaker
+	if _, err := rh.From(context.Background(), "Anarchism"); errors.Cause(err) != ErrCircuitOpen || requests != 3 {
+		t.Error("From should stop after 3 failed requests, instead it returns", err, "after", requests, "requests")
+	}
+	clock.Advance(30 * time.Second)
+	if _, err := rh.From(context.Background(), "Anarchism"); errors.Cause(err) != ErrCircuitOpen || requests != 3 {
+		t.Error("From should fail immediately while the breaker is open, instead it returns", err, "after", requests, "requests")
+	}
+
+	//A failed probe keeps it open
+	clock.Advance(time.Minute)
+	if _, err := rh.From(context.Background(), "Anarchism"); errors.Cause(err) != ErrCircuitOpen || requests != 4 {
+		t.Error("From should probe once, instead it returns", err, "after", requests, "requests")
+	}
+
+	//A successful probe closes it
+	atomic.StoreInt32(&healthy, 1)
+	clock.Advance(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := rh.From(context.Background(), "Anarchism"); err != nil {
+			t.Error("From should succeed once the wiki recovers, instead it returns", err)
+		}
+	}
+	if requests != 6 {
+		t.Error("Unexpected number of requests", requests)
+	}
+}
@@ -0,0 +1,65 @@
+package wikipage
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultNegativeCacheTTL is the default duration for which missing pages are remembered.
+const DefaultNegativeCacheTTL = time.Hour
+
+// negativeCache remembers missing pages, so that they can be reported without querying the API again.
+type negativeCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	key2Death map[string]time.Time
+	sweepSize int //Size at which expired entries are swept away
+}
+
+func newNegativeCache(ttl time.Duration) *negativeCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &negativeCache{ttl: ttl, key2Death: map[string]time.Time{}, sweepSize: 1024}
+}
+
+// Missing checks if key is known to be missing at time now.
+func (c *negativeCache) Missing(key string, now time.Time) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	death, ok := c.key2Death[key]
+	if ok && !now.Before(death) {
+		delete(c.key2Death, key)
+		ok = false
+	}
+	return ok
+}
+
+// Add remembers key as missing from time now.
+func (c *negativeCache) Add(key string, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key2Death[key] = now.Add(c.ttl)
+	if len(c.key2Death) < c.sweepSize {
+		return
+	}
+
+	//Sweep expired entries to keep memory bounded
+	for key, death := range c.key2Death {
+		if !now.Before(death) {
+			delete(c.key2Death, key)
+		}
+	}
+	c.sweepSize = 2 * len(c.key2Death)
+	if c.sweepSize < 1024 {
+		c.sweepSize = 1024
+	}
+}
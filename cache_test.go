@@ -0,0 +1,52 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNegativeCache(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found"}`)
+	}))
+	defer server.Close()
+
+	for _, ttl := range []time.Duration{time.Hour, 0} {
+		atomic.StoreInt32(&requests, 0)
+		rh := New("mytest", WithNegativeCacheTTL(ttl))
+		rh.title2Query = func(title string, life float64) string {
+			return server.URL + "?titles=" + title
+		}
+		for i := 0; i < 3; i++ {
+			expected := NotFoundDetails{Title: "0test1test2test3", Endpoint: "rest", Status: http.StatusNotFound}
+			if ttl > 0 && i > 0 {
+				expected = NotFoundDetails{Title: "0test1test2test3", Endpoint: "cache"}
+			}
+
+			_, err := rh.From(context.Background(), "0test1test2test3")
+			details, ok := NotFoundDetailsOf(err)
+			switch {
+			case !ok:
+				t.Error("From returns an unexpected error", err)
+			case details != expected:
+				t.Error("From returns", details, "expected", expected)
+			}
+		}
+
+		expected := int32(3)
+		if ttl > 0 {
+			expected = 1
+		}
+		if requests != expected {
+			t.Error("With ttl", ttl, "expected", expected, "requests, got", requests)
+		}
+	}
+}
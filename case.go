@@ -0,0 +1,32 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+)
+
+// CaseSensitiveTitles checks, through the siteinfo of the wiki, if it keeps the case of the first letter of titles: so that handlers for
+// arbitrary wikis can be set up accordingly through WithCaseSensitiveTitles.
+func (rh RequestHandler) CaseSensitiveTitles(ctx context.Context) (caseSensitive bool, err error) {
+	query := rh.apiQuery(url.Values{
+		"action": {"query"},
+		"meta":   {"siteinfo"},
+		"siprop": {"general"},
+	})
+
+	var data struct {
+		Query struct {
+			General struct {
+				Case string
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError("siteinfo"); err != nil {
+		return
+	}
+	return data.Query.General.Case == "case-sensitive", nil
+}
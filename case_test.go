@@ -0,0 +1,64 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestWithCaseSensitiveTitles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query(); {
+		case q.Get("meta") == "siteinfo" && q.Get("siprop") == "general":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"general":{"sitename":"Wiktionary","case":"case-sensitive"}}}`)
+		case r.URL.Path == "/api/rest_v1/page/summary/iPhone":
+			fmt.Fprint(w, `{"type":"standard","pageid":1,"title":"IPhone","extract":"A cached reply about another page."}`)
+		case r.URL.Path == "/api/rest_v1/page/summary/IPhone":
+			fmt.Fprint(w, `{"type":"standard","pageid":1,"title":"IPhone","extract":"IPhone is a misspelling."}`)
+		default:
+			t.Error("Unexpected query", r.URL)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	rh := New("en", WithBaseURL(server.URL), WithStrictTitleMatch())
+	if caseSensitive, err := rh.CaseSensitiveTitles(ctx); err != nil || !caseSensitive {
+		t.Error("CaseSensitiveTitles returns", caseSensitive, err)
+	}
+	if _, err := rh.From(ctx, "iPhone"); err != nil {
+		t.Error("By default titles should be compared up to the case of the first letter, instead From returns", err)
+	}
+
+	rh = New("en", WithBaseURL(server.URL), WithStrictTitleMatch(), WithCaseSensitiveTitles())
+	if _, err := rh.From(ctx, "iPhone"); err == nil {
+		t.Error("On case-sensitive wikis iPhone and IPhone are different pages, instead From accepts them as the same")
+	} else if _, ok := errors.Cause(err).(TitleMismatch); !ok {
+		t.Error("From returns", err, "expected TitleMismatch")
+	}
+	if p, err := rh.From(ctx, "IPhone"); err != nil || p.Title != "IPhone" {
+		t.Error("From returns", p.Title, err)
+	}
+}
+
+func TestSameTitle(t *testing.T) {
+	for _, test := range []struct {
+		title, otherTitle string
+		caseSensitive     bool
+		same              bool
+	}{
+		{"iPhone", "IPhone", false, true},
+		{"iPhone", "IPhone", true, false},
+		{"iPhone", "iPhone", true, true},
+		{"New_York", "New York", true, true},
+		{"IPhone", "iPhone", false, false},
+	} {
+		if same := sameTitle(test.title, test.otherTitle, test.caseSensitive); same != test.same {
+			t.Errorf("sameTitle(%v, %v, %v) returns %v, expected %v", test.title, test.otherTitle, test.caseSensitive, same, test.same)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package wikipage
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	templateRule   = regexp.MustCompile(`\{\{[^{}]*\}\}|\{\{|\}\}`)
+	citationRule   = regexp.MustCompile(`(?i)\[(?:\d+|[a-z]|(?:note|nb) \d+|citation needed|clarification needed)\]`)
+	coordinateRule = func() *regexp.Regexp {
+		degrees := func(hemispheres string) string {
+			return `-?\d{1,3}(?:\.\d+)?°(?:\s*\d{1,2}(?:\.\d+)?[′'])?(?:\s*\d{1,2}(?:\.\d+)?[″"])?\s*[` + hemispheres + `]`
+		}
+		pair := degrees("NS") + `[\s,;]*` + degrees("EW")
+		decimal := `-?\d{1,3}\.\d+\s*;\s*-?\d{1,3}\.\d+`
+		return regexp.MustCompile(`(?:Coordinates\s*:\s*)?` + pair + `(?:\s*[/;]\s*(?:` + pair + `|` + decimal + `))*`)
+	}()
+	listenRule           = regexp.MustCompile(`\(\s*(?:listen|ⓘ)\s*\)|\s*ⓘ`)
+	parenthesisOpenRule  = regexp.MustCompile(`\(\s*[,;:/]+\s*`)
+	parenthesisCloseRule = regexp.MustCompile(`\s*[,;:/]+\s*\)`)
+	emptyParenthesisRule = regexp.MustCompile(`\s*\(\s*\)`)
+	blankRule            = regexp.MustCompile(`[ \t\x{00a0}]+`)
+	punctuationSpaceRule = regexp.MustCompile(` ([,.;:])`)
+)
+
+// cleanExtract removes from a plain text extract the artifacts listed by WithCleanExtract.
+func cleanExtract(text string) string {
+	text = templateRule.ReplaceAllString(text, "")
+	text = citationRule.ReplaceAllString(text, "")
+	text = coordinateRule.ReplaceAllString(text, "")
+	text = listenRule.ReplaceAllString(text, "")
+	for previous := ""; previous != text; { //Parentheses may be left empty only after their punctuation is removed
+		previous = text
+		text = parenthesisOpenRule.ReplaceAllString(text, "(")
+		text = parenthesisCloseRule.ReplaceAllString(text, ")")
+		text = emptyParenthesisRule.ReplaceAllString(text, "")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = blankRule.ReplaceAllString(line, " ")
+		line = strings.Replace(strings.Replace(line, "( ", "(", -1), " )", ")", -1)
+		if line = strings.TrimSpace(punctuationSpaceRule.ReplaceAllString(line, "$1")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,67 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCleanExtract(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Anarchism is a political philosophy.":                                                             "Anarchism is a political philosophy.",
+		"Rome (Italian: Roma [ˈroːma] ( listen)) is the capital of Italy.[1][a]":                           "Rome (Italian: Roma [ˈroːma]) is the capital of Italy.",
+		"Coordinates: 41°53′24″N 12°29′32″E / 41.89000°N 12.49222°E / 41.89000; 12.49222\nRome is a city.": "Rome is a city.",
+		"Mount Etna (37.751°N 14.994°E) is a volcano.":                                                     "Mount Etna is a volcano.",
+		"Paris ( ; French: [paʁi] ⓘ) is the capital of France.":                                            "Paris (French: [paʁi]) is the capital of France.",
+		"The {{convert|3|km}} river {{ flows  into the sea[citation needed] .":                             "The river flows into the sea.",
+		"First  paragraph here.\n\n\n  Second paragraph ( , ).  ":                                          "First paragraph here.\nSecond paragraph.",
+		"Its value is 3.14[note 2] , roughly.":                                                             "Its value is 3.14, roughly.",
+	} {
+		if cleaned := cleanExtract(text); cleaned != expected {
+			t.Errorf("%q is cleaned as %q, expected %q", text, cleaned, expected)
+		}
+	}
+}
+
+func TestWithCleanExtract(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"query":{"pages":[{"pageid":25458,"ns":0,"title":"Rome","extract":"Rome (Italian: Roma ( listen)) is the capital city of Italy.[1] It has 2.8 million residents."}]}}`)
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		options  []Option
+		expected string
+	}{
+		{nil, "Rome (Italian: Roma ( listen)) is the capital city of Italy.[1] It has 2.8 million residents."},
+		{[]Option{WithCleanExtract()}, "Rome (Italian: Roma) is the capital city of Italy. It has 2.8 million residents."},
+	} {
+		rh := New("mytest", test.options...)
+		rh.title2Query = func(title string, life float64) string {
+			return server.URL + "?titles=" + title
+		}
+		if p, err := rh.From(context.Background(), "Rome"); err != nil || p.Abstract != test.expected {
+			t.Errorf("From returns %q, %v, expected %q", p.Abstract, err, test.expected)
+		}
+	}
+}
+
+func TestCleanExtractBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":25458,"ns":0,"title":"Rome","extract":"Rome (Italian: Roma ( listen)) is the capital city of Italy.[1]"}]}}`)
+	}))
+	defer server.Close()
+
+	const expected = "Rome (Italian: Roma) is the capital city of Italy."
+	rh := New("mytest", WithBaseURL(server.URL), WithCleanExtract())
+	title2Page, title2Error := rh.FromTitles(context.Background(), []string{"Rome"})
+	if p := title2Page["Rome"]; p.Abstract != expected {
+		t.Error("FromTitles returns", p, title2Error)
+	}
+	ID2Page, ID2Error := rh.FromIDs(context.Background(), []PageID{25458})
+	if p := ID2Page[25458]; p.Abstract != expected {
+		t.Error("FromIDs returns", p, ID2Error)
+	}
+}
@@ -0,0 +1,20 @@
+package wikipage
+
+import "time"
+
+// Clock is the source of time used by a RequestHandler for backoff schedules and cache expiry.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
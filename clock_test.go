@@ -0,0 +1,125 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// fakeClock is a Clock whose time flows only when waited upon.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestBackoffWithClock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for maxBackoff, expected := range map[time.Duration]time.Duration{0: DefaultMaxBackoff, 48 * time.Hour: 48 * time.Hour} {
+		clock := &fakeClock{now: time.Now()}
+		rh := New("mytest", WithClock(clock), WithMaxBackoff(maxBackoff))
+		rh.title2Query = func(title string, life float64) string {
+			return server.URL + "?titles=" + title
+		}
+
+		start := clock.Now()
+		_, err := rh.From(context.Background(), "Anarchism")
+		exhausted, ok := errors.Cause(err).(BackoffExhausted)
+		switch elapsed := clock.Now().Sub(start); {
+		case !ok:
+			t.Error("From should return a BackoffExhausted error, instead it returns", err)
+		case exhausted.Attempts < 2:
+			t.Error("From should retry, instead it made", exhausted.Attempts, "attempts")
+		case elapsed > expected || elapsed < expected*9/10:
+			t.Error("Backoff should span almost", expected, "instead it spans", elapsed)
+		}
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithClock(&fakeClock{now: time.Now()}), WithMaxAttempts(3))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+
+	_, err := rh.From(context.Background(), "Anarchism")
+	if exhausted, ok := errors.Cause(err).(BackoffExhausted); !ok || exhausted.Attempts != 3 || !exhausted.Temporary() {
+		t.Error("From should return a BackoffExhausted error after 3 attempts, instead it returns", err)
+	}
+	if requests != 3 {
+		t.Error("From should issue 3 requests, instead it issued", requests)
+	}
+}
+
+func TestMaxAttemptsFallback(t *testing.T) {
+	var endpoints []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/w/api.php") {
+			endpoints = append(endpoints, "rest")
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		endpoints = append(endpoints, "fallback")
+		fmt.Fprint(w, `{"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithClock(&fakeClock{now: time.Now()}), WithBaseURL(server.URL), WithMaxAttempts(2))
+	if p, err := rh.From(context.Background(), "Anarchism"); err != nil || p.ID != 12 {
+		t.Error("From returns", p, err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"rest", "fallback"}) {
+		t.Error("The last attempt should query the fall back API, instead the endpoints queried are", endpoints)
+	}
+}
+
+func TestNegativeCacheExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	c := newNegativeCache(time.Hour)
+	c.Add("Anarchism", clock.Now())
+	if !c.Missing("Anarchism", clock.Now()) {
+		t.Error("Anarchism should be remembered as missing")
+	}
+	clock.Advance(time.Hour)
+	if c.Missing("Anarchism", clock.Now()) {
+		t.Error("Anarchism should be expired")
+	}
+}
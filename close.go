@@ -0,0 +1,73 @@
+package wikipage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrClosed is the error returned by the calls of a RequestHandler aborted, or issued, after Close.
+var ErrClosed = errors.New("request handler closed")
+
+// Close aborts the calls in flight of the RequestHandler and of all its copies, e.g. on graceful shutdown: they fail with ErrClosed,
+// as every call issued afterwards. Handlers derived from it through Derive are closed as well, but not the other way round.
+// It never fails, and later calls to Close are no-ops.
+func (rh RequestHandler) Close() error {
+	rh.closer.Close()
+	return nil
+}
+
+// closer is the shared state making the copies of a handler abortable at once, a nil closer is never closed.
+// A closer counts as closed also when any of its parents is.
+type closer struct {
+	once   sync.Once
+	done   chan struct{}
+	parent *closer
+}
+
+func newCloser() *closer {
+	return &closer{done: make(chan struct{})}
+}
+
+// child returns a new closer, closed along with c but closable on its own.
+func (c *closer) child() *closer {
+	return &closer{done: make(chan struct{}), parent: c}
+}
+
+// Close aborts the contexts bound to c.
+func (c *closer) Close() {
+	if c != nil {
+		c.once.Do(func() { close(c.done) })
+	}
+}
+
+// Closed checks if c, or any of its parents, has been closed.
+func (c *closer) Closed() bool {
+	for ; c != nil; c = c.parent {
+		select {
+		case <-c.done:
+			return true
+		default:
+		}
+	}
+	return false
+}
+
+// bind returns a copy of ctx cancelled also when c, or any of its parents, is closed; the cancel function must be called to release it.
+func (c *closer) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	for ; c != nil; c = c.parent {
+		go func(done <-chan struct{}) {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}(c.done)
+	}
+	return ctx, cancel
+}
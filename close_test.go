@@ -0,0 +1,72 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("titles") == "" {
+			<-r.Context().Done() //The REST API hangs
+			return
+		}
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+
+	errs := make(chan error, 2)
+	for _, options := range [][]CallOption{nil, {ForceFallback()}} {
+		go func(options []CallOption) {
+			_, err := rh.From(context.Background(), "Anarchism", options...)
+			errs <- err
+		}(options)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := rh.Close(); err != nil {
+		t.Error("Close returns", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if errors.Cause(err) != ErrClosed {
+				t.Error("Calls in flight should fail with ErrClosed, instead they return", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close should abort calls in flight")
+		}
+	}
+
+	if _, err := rh.FullText(context.Background(), "Anarchism"); errors.Cause(err) != ErrClosed {
+		t.Error("Calls after Close should fail with ErrClosed, instead they return", err)
+	}
+	rh.Close()
+}
+
+func TestCloseCachedTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found","title":"Not found."}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithBaseURL(server.URL))
+	if _, err := rh.From(context.Background(), "Missing"); err == nil {
+		t.Fatal("From should fail on a missing page")
+	}
+	rh.Close()
+	if _, err := rh.From(context.Background(), "Missing"); errors.Cause(err) != ErrClosed {
+		t.Error("Calls after Close should fail with ErrClosed, even for pages remembered as missing, instead they return", err)
+	}
+}
@@ -0,0 +1,102 @@
+package wikipage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// flightGroup coalesces concurrent lookups with the same key into a single one.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a lookup shared by one or more waiters.
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	l       lookup
+	err     error
+	expired bool //The shared context expired before the lookup was completed
+}
+
+// Do executes fn, unless a lookup with the same key is already in flight, in which case it waits for its result.
+// The shared lookup is detached from the callers' contexts: it carries the values of the context of the caller that started it, it's bound to its deadline and
+// it's canceled as soon as all the waiters are gone, so that a caller is never held beyond its own deadline.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) (lookup, error)) (lookup, error) {
+	for {
+		f := g.join(ctx, key, fn)
+		select {
+		case <-f.done:
+			if f.expired && ctx.Err() == nil {
+				continue //The lookup was started by a caller with a closer deadline, try again
+			}
+			return f.l, f.err
+		case <-ctx.Done():
+			g.leave(key, f)
+			return lookup{}, errors.WithStack(ctx.Err())
+		}
+	}
+}
+
+func (g *flightGroup) join(ctx context.Context, key string, fn func(ctx context.Context) (lookup, error)) *flight {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls == nil {
+		g.calls = map[string]*flight{}
+	}
+
+	f, ok := g.calls[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(detached{ctx})
+		if deadline, ok := ctx.Deadline(); ok {
+			fctx, cancel = context.WithDeadline(detached{ctx}, deadline)
+		}
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+
+		go func() {
+			defer cancel()
+			f.l, f.err = fn(fctx)
+			f.expired = f.err != nil && fctx.Err() != nil
+
+			g.mu.Lock()
+			if g.calls[key] == f {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	return f
+}
+
+func (g *flightGroup) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+
+	//Nobody is waiting anymore
+	f.cancel()
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
+
+// detached is a context carrying the values of its parent, but neither its deadline nor its cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (deadline time.Time, ok bool) { return }
+func (detached) Done() <-chan struct{}                   { return nil }
+func (detached) Err() error                              { return nil }
+func (d detached) Value(key interface{}) interface{}     { return d.parent.Value(key) }
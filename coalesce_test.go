@@ -0,0 +1,60 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescing(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(200 * time.Millisecond)
+		p, _ := generatePage(1)
+		json.NewEncoder(w).Encode(p)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?pageids=" + title
+	}
+
+	//A caller with a short deadline must neither wait for nor disrupt the others
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := rh.From(ctx, "1"); err == nil {
+		t.Error("From should fail for the deadline")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+			defer cancel()
+			p, err := rh.From(ctx, "1")
+			expected, _ := generatePage(1)
+			expected.RequestedTitle, expected.NormalizedTitle = "1", "1"
+			switch {
+			case err != nil:
+				t.Error("From returns ", err)
+			case !reflect.DeepEqual(p, expected):
+				t.Error("From returns", p, "expected", expected)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests > 2 {
+		t.Error("Concurrent calls should share the lookup, got", requests, "requests")
+	}
+}
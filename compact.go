@@ -0,0 +1,22 @@
+package wikipage
+
+// compactExtras are the extras retrieved in compact mode, along with the page itself.
+const compactExtras = ExtraDescription | ExtraLength
+
+// Compact makes the call retrieve only the metadata of pages, without any extract, through the smallest possible query to the fall back API:
+// the returned WikiPages have ID, Title, Namespace, Description and Length, while Abstract and AbstractScope are empty.
+// Combined with FromTitles it resolves large lists of titles into titles and descriptions cheaply, 50 at a time.
+// Further extras provided by the fall back API may be requested through Include, the others are left empty.
+func Compact() CallOption {
+	return func(c *callConfig) {
+		c.compact = true
+	}
+}
+
+// compacted clears what is left of the abstract of p in compact mode.
+func (rh RequestHandler) compacted(p WikiPage) WikiPage {
+	if rh.compact {
+		p.Abstract, p.AbstractScope, p.Truncated = "", "", false
+	}
+	return p
+}
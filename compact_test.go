@@ -0,0 +1,61 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		_, intro := q["exintro"]
+		switch {
+		case r.URL.Path != "/w/api.php" || intro || q.Get("prop") != "description|info":
+			t.Error("Unexpected query", r.URL)
+		case q.Get("titles") == "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","description":"Political philosophy","length":140000}]}}`)
+		case q.Get("titles") == "Anarchism|USA|Missing":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"redirects":[{"from":"USA","to":"United States"}],"pages":[`+
+				`{"pageid":12,"ns":0,"title":"Anarchism","description":"Political philosophy","length":140000},`+
+				`{"pageid":3434750,"ns":0,"title":"United States","description":"Country in North America","length":400000},`+
+				`{"ns":0,"title":"Missing","missing":true}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Missing","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+
+	p, err := rh.From(ctx, "Anarchism", Compact())
+	switch {
+	case err != nil:
+		t.Error("From fails in compact mode:", err)
+	case p.ID != 12 || p.Title != "Anarchism" || p.Description != "Political philosophy" || p.Length != 140000:
+		t.Errorf("From returns unexpected metadata in compact mode: %+v", p)
+	case p.Abstract != "" || p.AbstractScope != "" || p.Truncated:
+		t.Errorf("From returns an abstract in compact mode: %+v", p)
+	}
+	if _, err := rh.From(ctx, "Missing", Compact()); err == nil {
+		t.Error("From should fail on a missing page in compact mode")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("From returns", err, "expected not found")
+	}
+
+	title2Page, title2Error := rh.FromTitles(ctx, []string{"Anarchism", "USA", "Missing"}, Compact())
+	switch {
+	case len(title2Page) != 2 || len(title2Error) != 1:
+		t.Errorf("FromTitles returns %v pages and %v errors in compact mode, expected 2 and 1: %v", len(title2Page), len(title2Error), title2Error)
+	case title2Page["USA"].Title != "United States" || title2Page["USA"].Description != "Country in North America":
+		t.Errorf("FromTitles returns unexpected metadata for a redirect in compact mode: %+v", title2Page["USA"])
+	case title2Page["Anarchism"].AbstractScope != "":
+		t.Errorf("FromTitles returns an abstract in compact mode: %+v", title2Page["Anarchism"])
+	}
+}
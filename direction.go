@@ -0,0 +1,26 @@
+package wikipage
+
+// rtlLanguages is the set of codes of the Wikipedias written right to left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "arc": true, "ary": true, "arz": true, "azb": true, "ckb": true, "dv": true, "fa": true, "glk": true, "he": true,
+	"ks": true, "mzn": true, "nqo": true, "pnb": true, "ps": true, "sd": true, "skr": true, "ug": true, "ur": true, "yi": true,
+}
+
+// Direction returns the direction, "ltr" or "rtl", of the text of the Wikipedia with the specified language code.
+func Direction(lang string) string {
+	if rtlLanguages[lang] {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// localize fills the language and direction of p, if the API didn't report them, from the language of the handler.
+func (rh RequestHandler) localize(p WikiPage) WikiPage {
+	if p.Lang == "" {
+		p.Lang = rh.lang
+	}
+	if p.Dir == "" {
+		p.Dir = Direction(p.Lang)
+	}
+	return p
+}
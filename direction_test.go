@@ -0,0 +1,40 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDirection(t *testing.T) {
+	for lang, expected := range map[string]string{"ar": "rtl", "he": "rtl", "fa": "rtl", "en": "ltr", "zh": "ltr", "": "ltr"} {
+		if dir := Direction(lang); dir != expected {
+			t.Error("Direction of", lang, "returns", dir, "expected", expected)
+		}
+	}
+}
+
+func TestLangAndDir(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("titles") != "" {
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":12,"ns":0,"title":"لاسلطوية","extract":"اللاسلطوية فلسفة سياسية."}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"type":"standard","pageid":12,"title":"لاسلطوية","extract":"اللاسلطوية فلسفة سياسية.","lang":"arz","dir":"rtl"}`)
+	}))
+	defer server.Close()
+
+	rh := New("ar")
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	for expected, options := range map[string][]CallOption{"arz": nil, "ar": {ForceFallback()}} {
+		switch p, err := rh.From(context.Background(), "لاسلطوية", options...); {
+		case err != nil:
+			t.Error("From", options, "returns", err)
+		case p.Lang != expected || p.Dir != "rtl":
+			t.Error("From", options, "returns lang", p.Lang, "and dir", p.Dir, "expected", expected, "and rtl")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package wikipage
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ParsePageTitles streams the titles of the articles (main namespace, redirects excluded) found in a pages-articles XML dump.
+// Dumps are parsed incrementally, so memory usage doesn't depend on their size. Titles must be drained until the channel is closed,
+// afterwards the error channel yields the parsing error, if any, and is closed as well.
+func ParsePageTitles(r io.Reader) (<-chan string, <-chan error) {
+	titles, errs := make(chan string, 64), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(titles)
+
+		decoder := xml.NewDecoder(r)
+		for {
+			token, err := decoder.Token()
+			switch {
+			case err == io.EOF:
+				return
+			case err != nil:
+				errs <- errors.Wrap(err, "error while parsing dump")
+				return
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok || start.Name.Local != "page" {
+				continue
+			}
+
+			var page struct {
+				Title    string    `xml:"title"`
+				NS       int       `xml:"ns"`
+				Redirect *struct{} `xml:"redirect"`
+			}
+			if err = decoder.DecodeElement(&page, &start); err != nil {
+				errs <- errors.Wrap(err, "error while parsing dump")
+				return
+			}
+			if page.NS == 0 && page.Redirect == nil {
+				titles <- page.Title
+			}
+		}
+	}()
+
+	return titles, errs
+}
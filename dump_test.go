@@ -0,0 +1,59 @@
+package wikipage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.10/" version="0.10" xml:lang="en">
+  <siteinfo>
+    <sitename>Wikipedia</sitename>
+  </siteinfo>
+  <page>
+    <title>AccessibleComputing</title>
+    <ns>0</ns>
+    <id>10</id>
+    <redirect title="Computer accessibility" />
+    <revision><id>854851586</id><text xml:space="preserve">#REDIRECT [[Computer accessibility]]</text></revision>
+  </page>
+  <page>
+    <title>Anarchism</title>
+    <ns>0</ns>
+    <id>12</id>
+    <revision><id>863126555</id><text xml:space="preserve">'''Anarchism''' is a political philosophy...</text></revision>
+  </page>
+  <page>
+    <title>Talk:Anarchism</title>
+    <ns>1</ns>
+    <id>13</id>
+    <revision><id>863126556</id><text xml:space="preserve">...</text></revision>
+  </page>
+  <page>
+    <title>Autism</title>
+    <ns>0</ns>
+    <id>25</id>
+    <revision><id>863126557</id><text xml:space="preserve">'''Autism''' is...</text></revision>
+  </page>
+</mediawiki>`
+
+func TestParsePageTitles(t *testing.T) {
+	titles, errs := ParsePageTitles(strings.NewReader(dump))
+	var got []string
+	for title := range titles {
+		got = append(got, title)
+	}
+	if err := <-errs; err != nil {
+		t.Error("ParsePageTitles returns ", err)
+	}
+	if expected := []string{"Anarchism", "Autism"}; !reflect.DeepEqual(got, expected) {
+		t.Error("ParsePageTitles returns", got, "expected", expected)
+	}
+
+	titles, errs = ParsePageTitles(strings.NewReader(dump[:len(dump)/2]))
+	for range titles {
+	}
+	if err := <-errs; err == nil {
+		t.Error("ParsePageTitles should fail on truncated dumps")
+	}
+}
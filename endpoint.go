@@ -0,0 +1,59 @@
+package wikipage
+
+import (
+	"context"
+)
+
+// Endpoint is the API From queries for a page.
+type Endpoint int
+
+const (
+	// EndpointAuto lets From choose: the REST API first, then the fall back API for the last quarter of the retries.
+	EndpointAuto Endpoint = iota
+	// EndpointREST is the REST API, serving richer summaries.
+	EndpointREST
+	// EndpointFallback is the action API, serving plain text extracts of the article introduction.
+	EndpointFallback
+)
+
+func (e Endpoint) String() string {
+	switch e {
+	case EndpointREST:
+		return "rest"
+	case EndpointFallback:
+		return "fallback"
+	default:
+		return "auto"
+	}
+}
+
+// fallbackLife is the share of the retries left below which From switches to the fall back API.
+const fallbackLife = 0.25
+
+// endpointAt returns the endpoint queried when life, the share of the retries left from 1 down to 0, remains.
+func endpointAt(life float64) Endpoint {
+	if life < fallbackLife {
+		return EndpointFallback
+	}
+	return EndpointREST
+}
+
+// life returns the life passed to query builders to select e, or auto if e is EndpointAuto.
+func (e Endpoint) life(auto float64) float64 {
+	switch e {
+	case EndpointREST:
+		return 1
+	case EndpointFallback:
+		return 0
+	default:
+		return auto
+	}
+}
+
+// FromEndpoint is like From, but every attempt queries the specified endpoint, instead of the one chosen by From according to the retries left.
+// It's meant for testing and for callers needing deterministic replies, e.g. FromEndpoint(ctx, title, EndpointFallback) is the same as From(ctx, title, ForceFallback()).
+func (rh RequestHandler) FromEndpoint(ctx context.Context, title string, endpoint Endpoint, options ...CallOption) (WikiPage, error) {
+	return rh.From(ctx, title, append(options[:len(options):len(options)], func(c *callConfig) {
+		c.endpoint = endpoint
+	})...)
+}
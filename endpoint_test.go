@@ -0,0 +1,38 @@
+package wikipage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEndpointAt(t *testing.T) {
+	for life, expected := range map[float64]Endpoint{1: EndpointREST, 0.5: EndpointREST, fallbackLife: EndpointREST, 0.2: EndpointFallback, 0: EndpointFallback} {
+		if endpoint := endpointAt(life); endpoint != expected {
+			t.Error("endpointAt(", life, ") returns", endpoint, "expected", expected)
+		}
+	}
+}
+
+func TestFromEndpoint(t *testing.T) {
+	rh := New("en")
+	for endpoint, prefix := range map[Endpoint]string{EndpointREST: "https://en.wikipedia.org/api/rest_v1/", EndpointFallback: "https://en.wikipedia.org/w/api.php?"} {
+		if rh, _ := rh.with(func(c *callConfig) { c.endpoint = endpoint }); !strings.HasPrefix(rh.title2Query("Anarchism", 0.1), prefix) {
+			t.Error("Endpoint", endpoint, "should be queried, got", rh.title2Query("Anarchism", 0.1))
+		}
+	}
+
+	var lives []float64
+	rh.title2Query = func(title string, life float64) string {
+		lives = append(lives, life)
+		return "http://" + address + "?pageids=" + title
+	}
+	if _, err := rh.FromEndpoint(context.Background(), "1", EndpointREST); err != nil {
+		t.Error("FromEndpoint returns ", err)
+	}
+	for _, life := range lives {
+		if endpointAt(life) != EndpointREST {
+			t.Error("FromEndpoint should query the REST API, got life", life)
+		}
+	}
+}
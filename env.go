@@ -0,0 +1,41 @@
+package wikipage
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/time/rate"
+)
+
+// Environment variables overriding the built-in defaults, read once at package initialization so that deployments may tune them without code changes.
+// Explicit settings take precedence: options override EnvUserAgent, assignments to DefaultRate and DefaultBurst override EnvRate and EnvBurst.
+// Values that can't be parsed are ignored.
+const (
+	EnvUserAgent = "WIKIPAGE_USER_AGENT" //User-Agent of every request, instead of DefaultUserAgent
+	EnvRate      = "WIKIPAGE_RATE"       //Requests per second of the shared limiter, instead of 150
+	EnvBurst     = "WIKIPAGE_BURST"      //Burst of the shared limiter, instead of 1
+)
+
+// envUserAgent is the User-Agent set by New, DefaultUserAgent unless overridden through EnvUserAgent.
+var envUserAgent = DefaultUserAgent
+
+func init() {
+	loadEnv(os.LookupEnv)
+}
+
+// loadEnv overrides the built-in defaults with the environment variables found through lookup.
+func loadEnv(lookup func(key string) (string, bool)) {
+	if userAgent, ok := lookup(EnvUserAgent); ok {
+		envUserAgent = userAgent
+	}
+	if value, ok := lookup(EnvRate); ok {
+		if r, err := strconv.ParseFloat(value, 64); err == nil && r > 0 {
+			DefaultRate = rate.Limit(r)
+		}
+	}
+	if value, ok := lookup(EnvBurst); ok {
+		if burst, err := strconv.Atoi(value); err == nil && burst > 0 {
+			DefaultBurst = burst
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package wikipage
+
+import (
+	"testing"
+)
+
+func TestLoadEnv(t *testing.T) {
+	userAgent, r, burst := envUserAgent, DefaultRate, DefaultBurst
+	defer func() {
+		envUserAgent, DefaultRate, DefaultBurst = userAgent, r, burst
+	}()
+
+	env := map[string]string{EnvUserAgent: "MyBot/1.0 (bot@example.org)", EnvRate: "12.5", EnvBurst: "4"}
+	loadEnv(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	switch {
+	case envUserAgent != "MyBot/1.0 (bot@example.org)":
+		t.Error("Unexpected User-Agent", envUserAgent)
+	case DefaultRate != 12.5 || DefaultBurst != 4:
+		t.Error("Unexpected rate and burst", DefaultRate, DefaultBurst)
+	}
+	if rh := New("mytest"); rh.userAgent != envUserAgent {
+		t.Error("New should use the User-Agent from the environment, got", rh.userAgent)
+	}
+	if rh := New("mytest", WithUserAgent("Explicit")); rh.userAgent != "Explicit" {
+		t.Error("Options should override the environment, got", rh.userAgent)
+	}
+
+	env = map[string]string{EnvRate: "fast", EnvBurst: "-1"}
+	loadEnv(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	if DefaultRate != 12.5 || DefaultBurst != 4 {
+		t.Error("Invalid values should be ignored, got", DefaultRate, DefaultBurst)
+	}
+}
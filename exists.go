@@ -0,0 +1,78 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// Exists checks if the article with the specified title exists, following redirects. It issues a minimal query, without retrieving any content,
+// and it's much cheaper than From: invalid titles are reported as not existing.
+func (rh RequestHandler) Exists(ctx context.Context, title string) (exists bool, err error) {
+	query := rh.apiQuery(url.Values{
+		"action":    {"query"},
+		"redirects": {""},
+		"titles":    {title},
+	})
+
+	var data struct {
+		Query struct {
+			Pages []struct {
+				Missing, Invalid bool
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	return len(data.Query.Pages) > 0 && !data.Query.Pages[0].Missing && !data.Query.Pages[0].Invalid, nil
+}
+
+// ExistingIDs returns the subset of the specified page IDs belonging to existing pages, issuing a minimal query for every batch of 50 IDs.
+// Bitmaps keep memory usage low even for millions of sparse IDs.
+func (rh RequestHandler) ExistingIDs(ctx context.Context, IDs *roaring.Bitmap) (existing *roaring.Bitmap, err error) {
+	existing = roaring.New()
+	sIDs := make([]string, 0, batchSize)
+	for it := IDs.Iterator(); it.HasNext(); {
+		sIDs = append(sIDs, strconv.FormatUint(uint64(it.Next()), 10))
+		if len(sIDs) < batchSize && it.HasNext() {
+			continue
+		}
+
+		query := rh.apiQuery(url.Values{
+			"action":  {"query"},
+			"pageids": {strings.Join(sIDs, "|")},
+		})
+		var data struct {
+			Query struct {
+				Pages []struct {
+					PageID  uint32
+					Missing bool
+				}
+			}
+			apiErrors
+		}
+		if err = rh.getJSON(ctx, query, &data); err != nil {
+			return nil, err
+		}
+		if apiErr := data.first(); apiErr != nil {
+			return nil, errors.Errorf("error with the following query: %v: %v (%v)", query, apiErr.Info, apiErr.Code)
+		}
+		for _, p := range data.Query.Pages {
+			if !p.Missing {
+				existing.Add(p.PageID)
+			}
+		}
+		sIDs = sIDs[:0]
+	}
+	return existing, nil
+}
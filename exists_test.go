@@ -0,0 +1,78 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+func TestExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if prop := r.URL.Query().Get("prop"); prop != "" {
+			t.Error("Exists shouldn't request any prop, got", prop)
+		}
+		switch r.URL.Query().Get("titles") {
+		case "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism"}]}}`)
+		case "Talk:":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"title":"Talk:","invalidreason":"The requested page title is empty or contains only the name of a namespace.","invalid":true}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Atlantis","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	for title, expected := range map[string]bool{"Anarchism": true, "Atlantis": false, "Talk:": false} {
+		if exists, err := rh.Exists(context.Background(), title); err != nil || exists != expected {
+			t.Error("Exists of", title, "returns", exists, err, "expected", expected)
+		}
+	}
+}
+
+func TestExistingIDs(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var pages []string
+		for _, sID := range strings.Split(r.URL.Query().Get("pageids"), "|") {
+			ID, _ := strconv.ParseUint(sID, 10, 32)
+			if _, ok := generatePage(uint32(ID)); ok {
+				pages = append(pages, fmt.Sprintf(`{"pageid":%v,"ns":0,"title":"%v"}`, ID, ID))
+			} else {
+				pages = append(pages, fmt.Sprintf(`{"pageid":%v,"missing":true}`, ID))
+			}
+		}
+		fmt.Fprintf(w, `{"batchcomplete":true,"query":{"pages":[%v]}}`, strings.Join(pages, ","))
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	IDs := roaring.New()
+	IDs.AddRange(1, 121)
+	existing, err := rh.ExistingIDs(context.Background(), IDs)
+	if err != nil {
+		t.Fatal("ExistingIDs returns", err)
+	}
+	expected := roaring.New()
+	for ID := uint32(1); ID < 121; ID++ {
+		if _, ok := generatePage(ID); ok {
+			expected.Add(ID)
+		}
+	}
+	if !existing.Equals(expected) {
+		t.Error("ExistingIDs returns", existing, "expected", expected)
+	}
+	if requests != 3 {
+		t.Error("ExistingIDs should issue 3 requests, issued", requests)
+	}
+}
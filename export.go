@@ -0,0 +1,48 @@
+package wikipage
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// exportedPage is the stable JSON schema of a WikiPage, independent of the wire format of the Wikipedia APIs.
+type exportedPage struct {
+	ID                PageID            `json:"id"`
+	Title             string            `json:"title"`
+	Abstract          string            `json:"abstract"`
+	Namespace         int               `json:"namespace"`
+	Truncated         bool              `json:"truncated"`
+	AbstractScope     string            `json:"abstract_scope,omitempty"`
+	AbstractHTML      string            `json:"abstract_html,omitempty"`
+	Lang              string            `json:"lang,omitempty"`
+	Dir               string            `json:"dir,omitempty"`
+	DisplayTitle      string            `json:"display_title"`
+	RequestedTitle    string            `json:"requested_title,omitempty"`
+	NormalizedTitle   string            `json:"normalized_title,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	DescriptionSource string            `json:"description_source,omitempty"`
+	Thumbnail         Image             `json:"thumbnail"`
+	Coordinates       Coordinates       `json:"coordinates"`
+	WikibaseItem      string            `json:"wikibase_item,omitempty"`
+	Length            uint32            `json:"length,omitempty"`
+	PageProps         map[string]string `json:"page_props,omitempty"`
+}
+
+// Export serializes p to JSON with a stable schema meant for persistence: the fields of WikiPage are mapped, in order, to
+// "id", "title", "abstract", "namespace", "truncated", "abstract_scope", "abstract_html", "lang", "dir", "display_title", "requested_title", "normalized_title",
+// "description", "description_source", "thumbnail" ("source", "width" and "height"), "coordinates" ("lat" and "lon"), "wikibase_item", "length"
+// and "page_props", with "abstract_scope", "abstract_html", "lang", "dir", the optional titles, "description", "description_source", "wikibase_item", "length" and "page_props" being omitted when empty. Import reverses it.
+func (p WikiPage) Export() ([]byte, error) {
+	data, err := json.Marshal(exportedPage(p))
+	return data, errors.WithStack(err)
+}
+
+// Import deserializes a WikiPage serialized by Export.
+func Import(data []byte) (p WikiPage, err error) {
+	var e exportedPage
+	if err = json.Unmarshal(data, &e); err != nil {
+		return WikiPage{}, errors.WithStack(err)
+	}
+	return WikiPage(e), nil
+}
@@ -0,0 +1,25 @@
+package wikipage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	p := WikiPage{ID: 12, Title: "Anarchism", Abstract: "Anarchism is a political philosophy.", DisplayTitle: "Anarchism", RequestedTitle: "anarchism", NormalizedTitle: "Anarchism"}
+	data, err := p.Export()
+	if err != nil {
+		t.Fatal("Export returns", err)
+	}
+	expected := `{"id":12,"title":"Anarchism","abstract":"Anarchism is a political philosophy.","namespace":0,"truncated":false,"display_title":"Anarchism","requested_title":"anarchism","normalized_title":"Anarchism","thumbnail":{"source":"","width":0,"height":0},"coordinates":{"lat":0,"lon":0}}`
+	if string(data) != expected {
+		t.Error("Export returns", string(data), "instead of", expected)
+	}
+
+	switch q, err := Import(data); {
+	case err != nil:
+		t.Error("Import returns", err)
+	case !reflect.DeepEqual(q, p):
+		t.Error("Import returns", q, "instead of", p)
+	}
+}
@@ -0,0 +1,85 @@
+package wikipage
+
+import (
+	"strconv"
+)
+
+// Extra is a set of optional properties of a WikiPage, which From retrieves in the same request of the page when asked through Include.
+type Extra uint
+
+const (
+	// ExtraDescription is the short description of the article, in WikiPage.Description, with its source in WikiPage.DescriptionSource.
+	ExtraDescription Extra = 1 << iota
+	// ExtraImage is the thumbnail of the lead image of the article, in WikiPage.Thumbnail.
+	ExtraImage
+	// ExtraCoordinates is the primary location of the article subject, in WikiPage.Coordinates.
+	ExtraCoordinates
+	// ExtraWikibase is the Wikidata item of the article, in WikiPage.WikibaseItem.
+	ExtraWikibase
+	// ExtraLength is the size in bytes of the article source, in WikiPage.Length. Only the fall back API provides it, so From queries it straight away.
+	ExtraLength
+	// ExtraPageProps are all the page properties of the article, e.g. "wikibase_item", "disambiguation" or "defaultsort", in WikiPage.PageProps;
+	// WikibaseItem and DisplayTitle are filled from them as well. Only the fall back API provides them, so From queries it straight away.
+	ExtraPageProps
+)
+
+// fallbackOnly are the extras provided only by the fall back API.
+const fallbackOnly = ExtraLength | ExtraPageProps
+
+// Coordinates represents a location on Earth.
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// extraThumbnailSize is the width of the thumbnails requested to the fall back API, matching the REST API one.
+const extraThumbnailSize = 320
+
+// fallbackParams returns the props and the query parameters requesting extras to the fall back API.
+func (extras Extra) fallbackParams() (props, params string) {
+	if extras&ExtraDescription != 0 {
+		props += "|description"
+	}
+	if extras&ExtraImage != 0 {
+		props += "|pageimages"
+		params += "&piprop=thumbnail&pithumbsize=" + strconv.Itoa(extraThumbnailSize)
+	}
+	if extras&ExtraCoordinates != 0 {
+		props += "|coordinates"
+		params += "&coprimary=primary"
+	}
+	switch {
+	case extras&ExtraPageProps != 0:
+		props += "|pageprops"
+	case extras&ExtraWikibase != 0:
+		props += "|pageprops"
+		params += "&ppprop=wikibase_item"
+	}
+	if extras&ExtraLength != 0 {
+		props += "|info"
+	}
+	return
+}
+
+// keep clears the extras of p not in extras, so that the page is the same whatever the API replying.
+func (extras Extra) keep(p WikiPage) WikiPage {
+	if extras&ExtraDescription == 0 {
+		p.Description, p.DescriptionSource = "", ""
+	}
+	if extras&ExtraImage == 0 {
+		p.Thumbnail = Image{}
+	}
+	if extras&ExtraCoordinates == 0 {
+		p.Coordinates = Coordinates{}
+	}
+	if extras&(ExtraWikibase|ExtraPageProps) == 0 {
+		p.WikibaseItem = ""
+	}
+	if extras&ExtraLength == 0 {
+		p.Length = 0
+	}
+	if extras&ExtraPageProps == 0 {
+		p.PageProps = nil
+	}
+	return p
+}
@@ -0,0 +1,91 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestIncludeFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if prop := r.URL.Query().Get("prop"); prop != "extracts|description|pageimages|coordinates|pageprops" {
+			t.Error("Unexpected prop", prop)
+		}
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","extract":"Rome is the capital city of Italy.","description":"Capital city of Italy","thumbnail":{"source":"https://upload.wikimedia.org/rome.jpg","width":320,"height":240},"coordinates":[{"lat":41.89,"lon":12.48,"primary":true,"globe":"earth"}],"pageprops":{"wikibase_item":"Q220"}}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	p, err := rh.From(context.Background(), "Rome", ForceFallback(), Include(ExtraDescription|ExtraImage|ExtraCoordinates|ExtraWikibase))
+	switch {
+	case err != nil:
+		t.Error("From returns", err)
+	case p.Description != "Capital city of Italy" || p.Thumbnail != Image{"https://upload.wikimedia.org/rome.jpg", 320, 240} || p.Coordinates != Coordinates{41.89, 12.48} || p.WikibaseItem != "Q220":
+		t.Error("From returns", p)
+	}
+}
+
+func TestIncludeREST(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"standard","title":"Rome","pageid":45,"namespace":{"id":0},"extract":"Rome is the capital city of Italy.","description":"Capital city of Italy","description_source":"local","thumbnail":{"source":"https://upload.wikimedia.org/rome.jpg","width":320,"height":240},"coordinates":{"lat":41.89,"lon":12.48},"wikibase_item":"Q220"}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	p, err := rh.From(context.Background(), "Rome", Include(ExtraDescription|ExtraCoordinates))
+	switch {
+	case err != nil:
+		t.Error("From returns", err)
+	case p.Description != "Capital city of Italy" || p.DescriptionSource != "local" || p.Coordinates != Coordinates{41.89, 12.48}:
+		t.Error("From returns", p)
+	case p.Thumbnail != Image{} || p.WikibaseItem != "":
+		t.Error("From returns extras not requested", p)
+	}
+}
+
+func TestIncludeLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if prop := r.URL.Query().Get("prop"); prop != "extracts|info" {
+			t.Error("Length should be requested to the fall back API, got prop", prop)
+		}
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","extract":"Rome is the capital city of Italy.","contentmodel":"wikitext","length":213475}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	switch p, err := rh.From(context.Background(), "Rome", Include(ExtraLength)); {
+	case err != nil:
+		t.Error("From returns", err)
+	case p.Length != 213475:
+		t.Error("From returns length", p.Length)
+	}
+}
+
+func TestIncludePageProps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if prop, ppprop := q.Get("prop"), q.Get("ppprop"); prop != "extracts|pageprops" || ppprop != "" {
+			t.Error("Page properties should be requested to the fall back API, got prop", prop, "and ppprop", ppprop)
+		}
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","extract":"Rome is the capital city of Italy.","pageprops":{"defaultsort":"Rome","displaytitle":"<i>Rome</i>","page_image_free":"Rome.jpg","wikibase_item":"Q220","wikibase-shortdesc":"Capital city of Italy"}}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	expected := map[string]string{"defaultsort": "Rome", "displaytitle": "<i>Rome</i>", "page_image_free": "Rome.jpg", "wikibase_item": "Q220", "wikibase-shortdesc": "Capital city of Italy"}
+	switch p, err := rh.From(context.Background(), "Rome", Include(ExtraPageProps)); {
+	case err != nil:
+		t.Error("From returns", err)
+	case !reflect.DeepEqual(p.PageProps, expected):
+		t.Error("From returns page properties", p.PageProps, "expected", expected)
+	case p.WikibaseItem != "Q220" || p.DisplayTitle != "<i>Rome</i>":
+		t.Error("From returns", p)
+	}
+}
@@ -0,0 +1,354 @@
+package wikipage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// FullText returns the whole plain text of the article with the specified title, following redirects.
+// Section headings are formatted as configured through WithSectionFormat.
+func (rh RequestHandler) FullText(ctx context.Context, title string) (text string, err error) {
+	rh = rh.fullContent()
+	var data struct {
+		Query struct {
+			Pages []mayMissingPage
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, rh.fullTextQuery(title), &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing {
+		return "", errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	return data.Query.Pages[0].Abstract, nil
+}
+
+// FullTextReader is like FullText, but it returns the plain text as a stream, decoded as the reply arrives instead of buffering it whole:
+// e.g. to tokenize articles of hundreds of KB at scale. The caller must close it, the request holds a connection and a slot of
+// WithMaxConcurrency until then. Errors arising while streaming, as the reply exceeding WithMaxBodyBytes, are returned by Read.
+// Compressed replies are decompressed transparently by the HTTP client.
+func (rh RequestHandler) FullTextReader(ctx context.Context, title string) (text io.ReadCloser, err error) {
+	rh = rh.fullContent()
+	query := rh.fullTextQuery(title)
+	resp, done, err := rh.open(ctx, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error with the following query: %v", query)
+	}
+	body := &recordingReader{reader: resp.Body}
+	if rh.maxBodyBytes > 0 {
+		body.reader = &cappedReader{reader: resp.Body, limit: rh.maxBodyBytes}
+	}
+	stream := &fullTextStream{body: body, done: done, status: resp.StatusCode}
+
+	switch body.err = unfollowedRedirect(resp); {
+	case body.err != nil:
+		err = body.err
+	case resp.StatusCode == http.StatusForbidden:
+		explanation, _ := ioutil.ReadAll(io.LimitReader(body, 64<<10))
+		err = forbidden(explanation)
+	case resp.StatusCode != http.StatusOK:
+		err = errors.Errorf("unexpected status %v", resp.StatusCode)
+	default:
+		stream.text, err = extractStream(body, title)
+	}
+	if err != nil {
+		if closeErr := stream.Close(); closeErr != nil {
+			err = closeErr //The request failed, as for the end of the context
+		}
+		if _, notFound := NotFound(err); notFound {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "error with the following query: %v", query)
+	}
+	return stream, nil
+}
+
+// fullTextQuery returns the query for the whole plain text of the article with the specified title.
+func (rh RequestHandler) fullTextQuery(title string) string {
+	params := url.Values{
+		"action":      {"query"},
+		"prop":        {"extracts"},
+		"explaintext": {""},
+		"redirects":   {""},
+		"titles":      {title},
+	}
+	if rh.sectionFormat != "" {
+		params.Set("exsectionformat", rh.sectionFormat)
+	}
+	return rh.apiQuery(params)
+}
+
+// fullTextStream is the text returned by FullTextReader, which releases the request once closed.
+type fullTextStream struct {
+	text   io.Reader
+	body   *recordingReader
+	done   func(status int, err error) error
+	status int
+	once   sync.Once
+}
+
+func (s *fullTextStream) Read(p []byte) (n int, err error) {
+	return s.text.Read(p)
+}
+
+func (s *fullTextStream) Close() (err error) {
+	s.once.Do(func() { err = s.done(s.status, s.body.err) })
+	return
+}
+
+// recordingReader records the first error, other than EOF, of the reader it wraps.
+type recordingReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *recordingReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return
+}
+
+// extractStream walks the reply to a full text query up to the extract of the page, returning its decoded value as a stream.
+// Replies are expected as formatversion=2, API errors and missing pages are reported as errors.
+func extractStream(body io.Reader, title string) (extract io.Reader, err error) {
+	dec := json.NewDecoder(body)
+	var errs apiErrors
+	walk := func(path ...string) (found bool, err error) { //Through nested objects
+		for i, key := range path {
+			if i > 0 {
+				if err = expectDelim(dec, '{'); err != nil {
+					return false, err
+				}
+			}
+			if found, err = seekKey(dec, key, &errs); !found || err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	if err = expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	found, err := walk("query", "pages")
+	if err == nil && found {
+		err = expectDelim(dec, '[')
+		if found = err == nil && dec.More(); found { //An empty array for invalid titles
+			err = expectDelim(dec, '{')
+		}
+	}
+	if err == nil && found {
+		found, err = walk("extract")
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case !found:
+		if err = errs.asError(title); err == nil {
+			err = errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+		}
+		return nil, err
+	}
+
+	//The value of the extract follows in the bytes left unparsed
+	reader := bufio.NewReader(io.MultiReader(dec.Buffered(), body))
+	for {
+		c, err := reader.ReadByte()
+		switch {
+		case err != nil:
+			return nil, errors.WithStack(MalformedResponse{err})
+		case c == ' ', c == '\t', c == '\r', c == '\n', c == ':':
+			continue
+		case c != '"':
+			return nil, errors.WithStack(MalformedResponse{errors.Errorf("extract isn't a string")})
+		}
+		return &jsonStringReader{reader: reader}, nil
+	}
+}
+
+// seekKey advances dec, within the current object, past the specified key, skipping the values of the others; errors are decoded into errs.
+// It returns false at the end of the object, which is consumed.
+func seekKey(dec *json.Decoder, key string, errs *apiErrors) (found bool, err error) {
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return false, errors.WithStack(MalformedResponse{err})
+		}
+		switch token {
+		case key:
+			return true, nil
+		case "error":
+			err = dec.Decode(&errs.Error)
+		case "errors":
+			err = dec.Decode(&errs.Errors)
+		default:
+			err = dec.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return false, errors.WithStack(MalformedResponse{err})
+		}
+	}
+	if _, err = dec.Token(); err != nil {
+		return false, errors.WithStack(MalformedResponse{err})
+	}
+	return false, nil
+}
+
+// expectDelim consumes the next token of dec, which must be the specified delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err == nil && token != delim {
+		err = errors.Errorf("unexpected %v instead of %v", token, delim)
+	}
+	if err != nil {
+		return errors.WithStack(MalformedResponse{err})
+	}
+	return nil
+}
+
+// jsonStringReader decodes a JSON string value incrementally, from after its opening quote up to its closing one.
+type jsonStringReader struct {
+	reader  *bufio.Reader
+	pending []byte //Decoded bytes not yet read
+	ended   bool
+}
+
+func (r *jsonStringReader) Read(p []byte) (n int, err error) {
+	for n < len(p) && err == nil {
+		if len(r.pending) > 0 {
+			copied := copy(p[n:], r.pending)
+			n, r.pending = n+copied, r.pending[copied:]
+			continue
+		}
+		if r.ended || (n > 0 && r.reader.Buffered() == 0) {
+			break //Don't block for more input with data at hand
+		}
+		err = r.decode()
+	}
+	if n == 0 && err == nil && r.ended {
+		err = io.EOF
+	}
+	return
+}
+
+// decode decodes into pending the next run of plain characters, or the next escape sequence, of the string.
+func (r *jsonStringReader) decode() error {
+	if buffered, _ := r.reader.Peek(r.reader.Buffered()); len(buffered) > 0 {
+		if i := bytes.IndexAny(buffered, `"\`); i != 0 {
+			if i < 0 {
+				i = len(buffered)
+			}
+			r.pending = append(r.pending, buffered[:i]...)
+			r.reader.Discard(i)
+			return nil
+		}
+	}
+
+	c, err := r.reader.ReadByte()
+	switch {
+	case err == io.EOF:
+		return errors.WithStack(MalformedResponse{io.ErrUnexpectedEOF})
+	case err != nil:
+		return err
+	case c == '"':
+		r.ended = true
+	case c != '\\':
+		r.pending = append(r.pending, c)
+	default:
+		rn, err := r.escape()
+		if err != nil {
+			return err
+		}
+		r.pending = append(r.pending, string(rn)...)
+	}
+	return nil
+}
+
+// escape decodes the escape sequence following a backslash.
+func (r *jsonStringReader) escape() (rune, error) {
+	c, err := r.reader.ReadByte()
+	if err != nil {
+		return 0, errors.WithStack(MalformedResponse{io.ErrUnexpectedEOF})
+	}
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		digits := make([]byte, 4)
+		if _, err := io.ReadFull(r.reader, digits); err != nil {
+			return 0, errors.WithStack(MalformedResponse{io.ErrUnexpectedEOF})
+		}
+		rn, ok := hexRune(digits)
+		switch {
+		case !ok:
+			return 0, errors.WithStack(MalformedResponse{errors.Errorf("invalid escape \\u%s", digits)})
+		case !utf16.IsSurrogate(rn):
+			return rn, nil
+		}
+		//Characters beyond the BMP are escaped as surrogate pairs, e.g. \ud83d\ude00: lone ones are decoded as U+FFFD, as encoding/json does
+		next, _ := r.reader.Peek(6)
+		if len(next) < 6 || next[0] != '\\' || next[1] != 'u' {
+			return utf8.RuneError, nil
+		}
+		low, ok := hexRune(next[2:])
+		if pair := utf16.DecodeRune(rn, low); ok && pair != utf8.RuneError {
+			r.reader.Discard(6)
+			return pair, nil
+		}
+		return utf8.RuneError, nil
+	default:
+		return 0, errors.WithStack(MalformedResponse{errors.Errorf("invalid escape \\%c", c)})
+	}
+}
+
+// hexRune decodes the four hexadecimal digits of a \u escape.
+func hexRune(digits []byte) (rune, bool) {
+	code, err := strconv.ParseUint(string(digits), 16, 16)
+	return rune(code), err == nil
+}
+
+// cappedReader is a reader failing with ResponseTooLarge after limit bytes.
+type cappedReader struct {
+	reader      io.Reader
+	limit, read int64
+}
+
+func (r *cappedReader) Read(p []byte) (n int, err error) {
+	if left := r.limit - r.read + 1; int64(len(p)) > left {
+		p = p[:left] //A byte more, to tell if the body exceeds the cap
+	}
+	n, err = r.reader.Read(p)
+	if r.read += int64(n); r.read > r.limit {
+		return 0, errors.WithStack(ResponseTooLarge{r.limit})
+	}
+	return
+}
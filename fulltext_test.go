@@ -0,0 +1,149 @@
+package wikipage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestFullText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("titles") != "Anarchism":
+			fmt.Fprint(w, `{"query":{"pages":[{"ns":0,"title":"0test1test2test3","missing":true}]}}`)
+		case q.Get("exsectionformat") == "wiki":
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is...\n\n== History ==\nIt..."}]}}`)
+		default:
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is...\n\nHistory\nIt..."}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	for format, expected := range map[string]string{"": "\n\nHistory\n", "wiki": "== History =="} {
+		rh := New("mytest", WithSectionFormat(format))
+		rh.baseURL = server.URL
+		text, err := rh.FullText(context.Background(), "Anarchism")
+		switch {
+		case err != nil:
+			t.Error("FullText returns ", err)
+		case !strings.Contains(text, expected):
+			t.Error("FullText with section format", format, "returns", text)
+		}
+	}
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	if _, err := rh.FullText(context.Background(), "0test1test2test3"); err == nil {
+		t.Error("FullText should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("FullText returns an unexpected error", err)
+	}
+	if query := New("en", WithSectionFormat("wiki")).title2Query("Anarchism", 0); !strings.Contains(query, "exsectionformat=wiki") {
+		t.Error("Section format is missing from the fall back query", query)
+	}
+}
+
+func TestFullTextReader(t *testing.T) {
+	const text = "Anarchism is a \"political\" philosophy.\n\nHistory\nL'anarchisme — 無政府主義 😀 <b>&</b>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("titles") {
+		case "Anarchism":
+			extract, _ := json.Marshal(text)
+			reply := `{"batchcomplete":true,"warnings":{"extracts":{"warnings":"HTML may be malformed"}},"query":{"normalized":[{"from":"anarchism","to":"Anarchism"}],` +
+				`"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract"  :  ` + string(extract) + `,"length":4210}]}}`
+			for len(reply) > 0 { //Stream the reply in chunks
+				n := 16
+				if n > len(reply) {
+					n = len(reply)
+				}
+				fmt.Fprint(w, reply[:n])
+				w.(http.Flusher).Flush()
+				reply = reply[n:]
+			}
+		case "Invalid|":
+			fmt.Fprint(w, `{"batchcomplete":true,"errors":[{"code":"invalidtitle","text":"Bad title."}]}`)
+		case "Huge":
+			fmt.Fprintf(w, `{"query":{"pages":[{"pageid":1,"ns":0,"title":"Huge","extract":"%v"}]}}`, strings.Repeat("a", 1<<10))
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Missing","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxConcurrency(1))
+	rh.baseURL = server.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ { //The slot is released on close
+		reader, err := rh.FullTextReader(ctx, "Anarchism")
+		if err != nil {
+			t.Fatal("FullTextReader returns", err)
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil || string(data) != text {
+			t.Errorf("FullTextReader streams %q, %v, expected %q", data, err, text)
+		}
+		if err = reader.Close(); err != nil {
+			t.Error("Close returns", err)
+		}
+	}
+
+	if _, err := rh.FullTextReader(ctx, "Missing"); err == nil {
+		t.Error("FullTextReader should fail on a missing page")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("FullTextReader returns", err, "expected not found")
+	}
+	if _, err := rh.FullTextReader(ctx, "Invalid|"); err == nil || !strings.Contains(err.Error(), "invalidtitle") {
+		t.Error("FullTextReader should report API errors, instead it returns", err)
+	}
+
+	rh = New("mytest", WithMaxBodyBytes(512))
+	rh.baseURL = server.URL
+	reader, err := rh.FullTextReader(ctx, "Huge")
+	if err != nil {
+		t.Fatal("FullTextReader returns", err)
+	}
+	defer reader.Close()
+	if _, err = ioutil.ReadAll(reader); err == nil {
+		t.Error("Reading past WithMaxBodyBytes should fail")
+	} else if _, tooLarge := errors.Cause(err).(ResponseTooLarge); !tooLarge {
+		t.Error("Reading past WithMaxBodyBytes returns", err)
+	}
+}
+
+func TestJSONStringReader(t *testing.T) {
+	for _, s := range []string{"", "plain", "quotes \" and \\ backslashes", "controls \n\t\r\b\f\x01", "<html> & 無政府主義", "emoji 😀 beyond the BMP", strings.Repeat("long ", 10000)} {
+		data, _ := json.Marshal(s)
+		for _, reader := range []io.Reader{bytes.NewReader(data[1:]), iotest.OneByteReader(bytes.NewReader(data[1:]))} {
+			decoded, err := ioutil.ReadAll(&jsonStringReader{reader: bufio.NewReader(reader)})
+			if err != nil || string(decoded) != s {
+				t.Errorf("%s is decoded as %q, %v", data, decoded, err)
+			}
+		}
+	}
+
+	for encoded, expected := range map[string]string{`è\/"`: "è/", `😀"`: "😀", `\ud83d x"`: "� x", `\ud83dA"`: "�A"} {
+		decoded, err := ioutil.ReadAll(&jsonStringReader{reader: bufio.NewReader(strings.NewReader(encoded))})
+		if err != nil || string(decoded) != expected {
+			t.Errorf("%s is decoded as %q, %v, expected %q", encoded, decoded, err, expected)
+		}
+	}
+	for _, encoded := range []string{`unterminated`, `\x"`, `\u12"`} {
+		if _, err := ioutil.ReadAll(&jsonStringReader{reader: bufio.NewReader(strings.NewReader(encoded))}); err == nil {
+			t.Errorf("%s should fail to decode", encoded)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HealthCheck verifies that both the REST API and the fall back API of the wiki are reachable and accept the handler requests,
+// by fetching the site informations and the summary of the main page. If any of them is unavailable it returns an Unhealthy error.
+func (rh RequestHandler) HealthCheck(ctx context.Context) error {
+	var unhealthy Unhealthy
+
+	var data struct {
+		Query struct {
+			General struct {
+				MainPage string
+			}
+		}
+		apiErrors
+	}
+	unhealthy.Query = rh.getJSON(ctx, rh.apiQuery(url.Values{"action": {"query"}, "meta": {"siteinfo"}}), &data)
+	if unhealthy.Query == nil {
+		unhealthy.Query = data.asError("siteinfo")
+	}
+
+	mainPage := data.Query.General.MainPage
+	if mainPage == "" {
+		mainPage = "Main Page"
+	}
+	var summary restSummary
+	unhealthy.REST = rh.getREST(ctx, mainPage, rh.restQuery("summary", mainPage), &summary)
+
+	if unhealthy.REST != nil || unhealthy.Query != nil {
+		return unhealthy
+	}
+	return nil
+}
+
+// Unhealthy is the error returned by HealthCheck, reporting which APIs are unavailable.
+type Unhealthy struct {
+	REST, Query error //Nil if available
+}
+
+func (err Unhealthy) Error() string {
+	var reports []string
+	if err.REST != nil {
+		reports = append(reports, fmt.Sprintf("REST API unavailable: %v", err.REST))
+	}
+	if err.Query != nil {
+		reports = append(reports, fmt.Sprintf("fall back API unavailable: %v", err.Query))
+	}
+	return strings.Join(reports, "; ")
+}
@@ -0,0 +1,40 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestHealthCheck(t *testing.T) {
+	restDown := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/w/api.php":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"general":{"mainpage":"Pagina principale","sitename":"Wikipedia"}}}`)
+		case restDown:
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		case r.URL.Path == "/api/rest_v1/page/summary/Pagina_principale":
+			fmt.Fprint(w, `{"type":"standard","title":"Pagina principale","pageid":1,"extract":""}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	if err := rh.HealthCheck(context.Background()); err != nil {
+		t.Error("HealthCheck returns ", err)
+	}
+
+	restDown = true
+	err := rh.HealthCheck(context.Background())
+	if unhealthy, ok := errors.Cause(err).(Unhealthy); !ok || unhealthy.REST == nil || unhealthy.Query != nil {
+		t.Error("HealthCheck should report the REST API as unavailable, instead it returns", err)
+	}
+}
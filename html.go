@@ -0,0 +1,39 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+)
+
+// HTML returns the rendered HTML of the article with the specified title, following redirects.
+// Warning: it's a way heavier call than From, as it transfers the whole article body, which may amount to several megabytes for long articles.
+// With WithMobile, it returns the whole page formatted for mobile devices by the REST API.
+func (rh RequestHandler) HTML(ctx context.Context, title string) (HTML string, err error) {
+	rh = rh.fullContent()
+	if rh.mobile {
+		body, err := rh.getRESTBody(ctx, title, rh.restQuery("mobile-html", title))
+		return string(body), err
+	}
+
+	query := rh.apiQuery(url.Values{
+		"action":    {"parse"},
+		"page":      {title},
+		"prop":      {"text"},
+		"redirects": {""},
+	})
+
+	var data struct {
+		Parse struct {
+			Text string
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+
+	if err = data.asError(title); err != nil {
+		return
+	}
+	return data.Parse.Text, nil
+}
@@ -0,0 +1,96 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query(); {
+		case q.Get("action") != "parse" || q.Get("prop") != "text":
+			t.Error("Unexpected query", r.URL)
+		case q.Get("page") == "Anarchism":
+			fmt.Fprint(w, `{"parse":{"title":"Anarchism","pageid":12,"text":"<p>Anarchism is...</p>"}}`)
+		default:
+			fmt.Fprint(w, `{"error":{"code":"missingtitle","info":"The page you specified doesn't exist."}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	HTML, err := rh.HTML(context.Background(), "Anarchism")
+	switch {
+	case err != nil:
+		t.Error("HTML returns ", err)
+	case HTML != "<p>Anarchism is...</p>":
+		t.Error("HTML returns", HTML)
+	}
+
+	_, err = rh.HTML(context.Background(), "0test1test2test3")
+	if _, ok := NotFound(err); !ok {
+		t.Error("HTML returns an unexpected error", err)
+	}
+}
+
+func TestMobileHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/rest_v1/page/mobile-html/Anarchism_(disambiguation)":
+			fmt.Fprint(w, `<!DOCTYPE html><html><body><section data-mw-section-id="0"><p>Anarchism may refer to...</p></section></body></html>`)
+		default:
+			http.Error(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMobile())
+	rh.baseURL = server.URL
+
+	HTML, err := rh.HTML(context.Background(), "Anarchism (disambiguation)")
+	switch {
+	case err != nil:
+		t.Error("HTML returns ", err)
+	case HTML != `<!DOCTYPE html><html><body><section data-mw-section-id="0"><p>Anarchism may refer to...</p></section></body></html>`:
+		t.Error("HTML returns", HTML)
+	}
+
+	_, err = rh.HTML(context.Background(), "0test1test2test3")
+	if _, ok := NotFound(err); !ok {
+		t.Error("HTML returns an unexpected error", err)
+	}
+}
+
+func TestHTMLLongArticle(t *testing.T) {
+	text := "<p>" + strings.Repeat("Long article. ", (DefaultMaxBodyBytes+1<<20)/14) + "</p>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/w/api.php" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"parse": map[string]interface{}{"title": "Long", "pageid": 1, "text": text}})
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	rh := New("mytest", WithBaseURL(server.URL))
+	if HTML, err := rh.HTML(ctx, "Long"); err != nil || HTML != text {
+		t.Error("The default cap shouldn't apply to whole articles, instead HTML returns", len(HTML), "bytes,", err)
+	}
+
+	rh = New("mytest", WithBaseURL(server.URL), WithMaxBodyBytes(DefaultMaxBodyBytes))
+	if _, err := rh.HTML(ctx, "Long"); err == nil {
+		t.Error("HTML should honor the cap set through WithMaxBodyBytes")
+	} else if _, ok := errors.Cause(err).(ResponseTooLarge); !ok {
+		t.Error("HTML returns", err, "expected ResponseTooLarge")
+	}
+}
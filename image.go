@@ -0,0 +1,163 @@
+package wikipage
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Image represents an image of a Wikipedia article.
+type Image struct {
+	Source string `json:"source"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Thumbnail returns the thumbnail of the lead image of the article with the specified title, scaled to the requested width and following redirects.
+// If the article has no lead image, Thumbnail returns a nil image.
+func (rh RequestHandler) Thumbnail(ctx context.Context, title string, width int) (image *Image, err error) {
+	query := rh.apiQuery(url.Values{
+		"action":      {"query"},
+		"prop":        {"pageimages"},
+		"piprop":      {"thumbnail"},
+		"pithumbsize": {strconv.Itoa(width)},
+		"redirects":   {""},
+		"titles":      {title},
+	})
+
+	var data struct {
+		Query struct {
+			Pages []struct {
+				Missing   bool
+				Thumbnail *Image
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing {
+		return nil, errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	return data.Query.Pages[0].Thumbnail, nil
+}
+
+// LeadImage returns the lead image of the article with the specified title, following redirects, at its original size: as reported
+// by the REST summary or, since the summary omits it for many articles, by the page images of the action API. The thumbnail is returned
+// in place of the original when only that is available, a nil image only when the article has no lead image at all.
+func (rh RequestHandler) LeadImage(ctx context.Context, title string) (image *Image, err error) {
+	var summary struct {
+		OriginalImage *Image `json:"originalimage"`
+		Thumbnail     *Image
+	}
+	err = rh.getREST(ctx, title, rh.restQuery("summary", title), &summary)
+	switch _, notFound := NotFound(err); {
+	case notFound:
+		return nil, err
+	case err == nil && summary.OriginalImage != nil:
+		return summary.OriginalImage, nil
+	case err == nil && summary.Thumbnail != nil:
+		return summary.Thumbnail, nil
+	}
+
+	query := rh.apiQuery(url.Values{
+		"action":      {"query"},
+		"prop":        {"pageimages"},
+		"piprop":      {"original|thumbnail"},
+		"pithumbsize": {strconv.Itoa(extraThumbnailSize)},
+		"redirects":   {""},
+		"titles":      {title},
+	})
+	var data struct {
+		Query struct {
+			Pages []struct {
+				Missing   bool
+				Original  *Image
+				Thumbnail *Image
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	switch {
+	case len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing:
+		return nil, errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	case data.Query.Pages[0].Original != nil:
+		return data.Query.Pages[0].Original, nil
+	default:
+		return data.Query.Pages[0].Thumbnail, nil
+	}
+}
+
+// ImageInfo holds what is needed to credit an image when reusing it.
+type ImageInfo struct {
+	URL         string //URL of the original file
+	License     string //Short name of the license, e.g. "CC BY-SA 4.0"
+	Attribution string //Plain text credit, as required by the license or otherwise the author
+}
+
+// ImageInfo returns the URL, the license and the attribution of the file with the specified title (e.g. "File:Example.jpg"), as reported by its
+// extended metadata: files hosted on Wikimedia Commons are looked up there through the handler wiki. Files without metadata have empty License and Attribution.
+func (rh RequestHandler) ImageInfo(ctx context.Context, fileTitle string) (info *ImageInfo, err error) {
+	query := rh.apiQuery(url.Values{
+		"action":              {"query"},
+		"prop":                {"imageinfo"},
+		"iiprop":              {"url|extmetadata"},
+		"iiextmetadatafilter": {"LicenseShortName|Attribution|Artist"},
+		"redirects":           {""},
+		"titles":              {fileTitle},
+	})
+
+	type metadata struct {
+		Value string
+	}
+	var data struct {
+		Query struct {
+			Pages []struct {
+				Imageinfo []struct {
+					URL         string
+					Extmetadata struct {
+						LicenseShortName, Attribution, Artist metadata
+					}
+				}
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(fileTitle); err != nil {
+		return
+	}
+
+	//Files hosted elsewhere are reported missing, but with their image info
+	if len(data.Query.Pages) == 0 || len(data.Query.Pages[0].Imageinfo) == 0 {
+		return nil, errors.WithStack(pageNotFound{title: fileTitle, endpoint: "query"})
+	}
+	ii := data.Query.Pages[0].Imageinfo[0]
+	attribution := ii.Extmetadata.Attribution.Value
+	if attribution == "" {
+		attribution = ii.Extmetadata.Artist.Value
+	}
+	return &ImageInfo{
+		URL:         ii.URL,
+		License:     ii.Extmetadata.LicenseShortName.Value,
+		Attribution: strings.TrimSpace(html.UnescapeString(tagRule.ReplaceAllString(attribution, ""))),
+	}, nil
+}
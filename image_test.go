@@ -0,0 +1,112 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query(); q.Get("titles") {
+		case "Anarchism":
+			fmt.Fprintf(w, `{"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","thumbnail":{"source":"https://upload.wikimedia.org/a.png","width":%v,"height":200}}]}}`, q.Get("pithumbsize"))
+		case "Imageless":
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":13,"ns":0,"title":"Imageless"}]}}`)
+		default:
+			fmt.Fprint(w, `{"query":{"pages":[{"ns":0,"title":"0test1test2test3","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	image, err := rh.Thumbnail(context.Background(), "Anarchism", 320)
+	switch {
+	case err != nil:
+		t.Error("Thumbnail returns ", err)
+	case image == nil || *image != Image{"https://upload.wikimedia.org/a.png", 320, 200}:
+		t.Error("Thumbnail returns", image)
+	}
+
+	if image, err = rh.Thumbnail(context.Background(), "Imageless", 320); err != nil || image != nil {
+		t.Error("Thumbnail should return no image, instead it returns", image, err)
+	}
+
+	if _, err = rh.Thumbnail(context.Background(), "0test1test2test3", 320); err == nil {
+		t.Error("Thumbnail should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("Thumbnail returns an unexpected error", err)
+	}
+}
+
+func TestLeadImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch title := strings.TrimPrefix(r.URL.Path, "/api/rest_v1/page/summary/"); {
+		case title == "Anarchism":
+			fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"thumbnail":{"source":"https://upload.wikimedia.org/thumb/a.png","width":320,"height":200},"originalimage":{"source":"https://upload.wikimedia.org/a.png","width":1600,"height":1000}}`)
+		case title == "Missing":
+			http.Error(w, "Not Found", http.StatusNotFound)
+		case r.URL.Path != "/w/api.php":
+			fmt.Fprintf(w, `{"type":"standard","title":"%v","pageid":13}`, title)
+		case r.URL.Query().Get("titles") == "Rome":
+			if piprop := r.URL.Query().Get("piprop"); piprop != "original|thumbnail" {
+				t.Error("Unexpected piprop", piprop)
+			}
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":13,"ns":0,"title":"Rome","thumbnail":{"source":"https://upload.wikimedia.org/thumb/r.jpg","width":320,"height":240},"original":{"source":"https://upload.wikimedia.org/r.jpg","width":800,"height":600}}]}}`)
+		default:
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":14,"ns":0,"title":"Imageless"}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	for title, expected := range map[string]Image{"Anarchism": {"https://upload.wikimedia.org/a.png", 1600, 1000}, "Rome": {"https://upload.wikimedia.org/r.jpg", 800, 600}} {
+		if image, err := rh.LeadImage(context.Background(), title); err != nil || image == nil || *image != expected {
+			t.Error("LeadImage of", title, "returns", image, err, "expected", expected)
+		}
+	}
+	if image, err := rh.LeadImage(context.Background(), "Imageless"); err != nil || image != nil {
+		t.Error("LeadImage should return no image, instead it returns", image, err)
+	}
+	if _, err := rh.LeadImage(context.Background(), "Missing"); err == nil {
+		t.Error("LeadImage should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("LeadImage returns an unexpected error", err)
+	}
+}
+
+func TestImageInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("titles") {
+		case "File:Bakunin.png":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":6,"title":"File:Bakunin.png","missing":true,"known":true,"imagerepository":"shared","imageinfo":[{"url":"https://upload.wikimedia.org/wikipedia/commons/b/b0/Bakunin.png","descriptionurl":"https://commons.wikimedia.org/wiki/File:Bakunin.png","extmetadata":{"LicenseShortName":{"value":"CC BY-SA 4.0","source":"commons-desc-page","hidden":""},"Artist":{"value":"<a href=\"//commons.wikimedia.org/wiki/User:Nadar\" title=\"User:Nadar\">F&eacute;lix Nadar</a>","source":"commons-desc-page"}}}]}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":6,"title":"File:Missing.png","missing":true,"imagerepository":""}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	info, err := rh.ImageInfo(context.Background(), "File:Bakunin.png")
+	switch expected := (ImageInfo{"https://upload.wikimedia.org/wikipedia/commons/b/b0/Bakunin.png", "CC BY-SA 4.0", "Félix Nadar"}); {
+	case err != nil:
+		t.Error("ImageInfo returns ", err)
+	case info == nil || *info != expected:
+		t.Error("ImageInfo returns", info, "expected", expected)
+	}
+
+	if _, err = rh.ImageInfo(context.Background(), "File:Missing.png"); err == nil {
+		t.Error("ImageInfo should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("ImageInfo returns an unexpected error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package wikipage
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NewValidated is like New, but it fails right away if lang isn't the code of an active Wikipedia, instead of failing on every request.
+// Codes are matched exactly, so they must be lower case and without spaces, and against a list bundled with the package:
+// Wikipedias opened after its last update can still be targeted through New.
+func NewValidated(lang string, options ...Option) (RequestHandler, error) {
+	if _, ok := languages[lang]; !ok {
+		return RequestHandler{}, errors.Errorf("%q isn't the language code of an active Wikipedia", lang)
+	}
+	return New(lang, options...), nil
+}
+
+// languages is the set of codes of the active Wikipedias, as found in their subdomains.
+var languages = func() map[string]struct{} {
+	codes := strings.Fields(`
+		ab ace ady af als alt am ami an ang ann anp ar arc ary arz as ast atj av avk awa ay az azb
+		ba ban bar bat-smg bcl be be-tarask bg bh bi bjn blk bm bn bo bpy br bs btm bug bxr
+		ca cbk-zam cdo ce ceb ch chr chy ckb co cr crh cs csb cu cv cy
+		da dag de dga din diq dsb dtp dty dv dz ee el eml en eo es et eu ext
+		fa fat ff fi fiu-vro fj fo fon fr frp frr fur fy ga gag gan gcr gd gl glk gn gom gor got gpe gu guc gur guw gv
+		ha hak haw he hi hif hr hsb ht hu hy hyw ia id ie ig igl ik ilo inh io is it iu ja jam jbo jv
+		ka kaa kab kbd kbp kcg kg ki kk kl km kn ko koi krc ks ksh ku kv kw ky
+		la lad lb lbe lez lfn lg li lij lld lmo ln lo lt ltg lv
+		mad mai map-bms mdf mg mhr mi min mk ml mn mni mnw mos mr mrj ms mt mwl my myv mzn
+		na nah nap nds nds-nl ne new nia nl nn no nov nqo nr nrm nso ny oc olo om or os
+		pa pag pam pap pcd pcm pdc pfl pi pih pl pms pnb pnt ps pt pwn qu
+		rm rmy rn ro roa-rup roa-tara ru rue rw sa sah sat sc scn sco sd se sg sh shi shn si simple sk skr sl sm smn sn so sq sr srn ss st stq su sv sw szl szy
+		ta tay tcy tdd te tet tg th ti tk tl tly tn to tpi tr trv ts tt tum tw ty tyv
+		udm ug uk ur uz ve vec vep vi vls vo wa war wo wuu xal xh xmf yi yo za zea zgh zh zh-classical zh-min-nan zh-yue zu`)
+
+	languages := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		languages[code] = struct{}{}
+	}
+	return languages
+}()
@@ -0,0 +1,18 @@
+package wikipage
+
+import (
+	"testing"
+)
+
+func TestNewValidated(t *testing.T) {
+	for _, lang := range []string{"en", "it", "simple", "zh-min-nan", "be-tarask"} {
+		if rh, err := NewValidated(lang); err != nil || rh.lang != lang {
+			t.Error("NewValidated of", lang, "returns", err)
+		}
+	}
+	for _, lang := range []string{"english", "En ", "EN", "", "mytest"} {
+		if _, err := NewValidated(lang); err == nil {
+			t.Error("NewValidated of", lang, "should fail")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package wikipage
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LeadParagraph returns the first paragraph of the article with the specified title, following redirects, as a single line of plain text:
+// e.g. a teaser, where the abstract of From may span the whole lead section and the cuts by length fall mid sentence.
+// Paragraphs are told apart on the HTML of the lead section, so that line breaks within a paragraph don't cut it and empty ones are skipped.
+func (rh RequestHandler) LeadParagraph(ctx context.Context, title string) (paragraph string, err error) {
+	query := rh.apiQuery(url.Values{
+		"action":    {"query"},
+		"prop":      {"extracts"},
+		"exintro":   {""},
+		"redirects": {""},
+		"titles":    {title},
+	})
+
+	var data struct {
+		Query struct {
+			Pages []mayMissingPage
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing {
+		return "", errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	return leadParagraph(data.Query.Pages[0].Abstract), nil
+}
+
+var (
+	paragraphElementRule = regexp.MustCompile(`(?is)<p(?:\s[^>]*)?>(.*?)</p>`)
+	spacesRule           = regexp.MustCompile(`\s+`)
+)
+
+// leadParagraph returns the first paragraph with some text of an extract, either HTML or plain text, as a single line of plain text.
+// Plain text paragraphs are separated by line breaks, HTML ones are p elements; HTML without them is split on line breaks as well.
+func leadParagraph(extract string) string {
+	var paragraphs []string
+	matches := paragraphElementRule.FindAllStringSubmatch(extract, -1)
+	for _, match := range matches {
+		paragraphs = append(paragraphs, html.UnescapeString(tagRule.ReplaceAllString(match[1], "")))
+	}
+	if len(matches) == 0 {
+		if strings.Contains(extract, "</") || strings.Contains(extract, "/>") {
+			extract = html.UnescapeString(tagRule.ReplaceAllString(strings.Replace(extract, "<br", "\n<br", -1), ""))
+		}
+		paragraphs = strings.Split(extract, "\n")
+	}
+
+	for _, paragraph := range paragraphs {
+		if paragraph = strings.TrimSpace(spacesRule.ReplaceAllString(paragraph, " ")); paragraph != "" {
+			return paragraph
+		}
+	}
+	return ""
+}
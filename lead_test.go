@@ -0,0 +1,53 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLeadParagraph(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		_, plain := q["explaintext"]
+		_, cut := q["exchars"]
+		_, intro := q["exintro"]
+		switch {
+		case plain || cut || !intro:
+			t.Error("Unexpected query", r.URL)
+		case q.Get("titles") == "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"<p class=\"mw-empty-elt\">\n</p>\n<p><b>Anarchism</b> is a political philosophy\nand movement &amp; more.</p>\n<p>It holds the state to be undesirable.</p>"}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Missing","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	if paragraph, err := rh.LeadParagraph(context.Background(), "Anarchism"); err != nil || paragraph != "Anarchism is a political philosophy and movement & more." {
+		t.Errorf("LeadParagraph returns %q, %v", paragraph, err)
+	}
+	if _, err := rh.LeadParagraph(context.Background(), "Missing"); err == nil {
+		t.Error("LeadParagraph should fail on a missing page")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("LeadParagraph returns", err, "expected not found")
+	}
+}
+
+func TestLeadParagraphOf(t *testing.T) {
+	for extract, expected := range map[string]string{
+		"": "",
+		"Rome is the capital of Italy.\nIt was founded in 753 BC.": "Rome is the capital of Italy.",
+		"\n\n  Rome is   the capital.\n\nHistory":                  "Rome is the capital.",
+		"<P>First <i>paragraph</i>.</P><p>Second.</p>":             "First paragraph.",
+		"<b>Rome</b> is the capital.<br/>It was founded.":          "Rome is the capital.",
+		"x < y and y > z\nSecond":                                  "x < y and y > z",
+	} {
+		if paragraph := leadParagraph(extract); paragraph != expected {
+			t.Errorf("The lead paragraph of %q is %q, expected %q", extract, paragraph, expected)
+		}
+	}
+}
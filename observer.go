@@ -0,0 +1,51 @@
+package wikipage
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Resolution summarizes how a call to From was resolved. A rising share of resolutions served by the fall back API signals problems with the REST API.
+type Resolution struct {
+	Title         string        `json:"title"`
+	ResolvedTitle string        `json:"resolved_title,omitempty"`
+	Status        string        `json:"status"`              //"found", "missing" or "error"
+	Endpoint      string        `json:"endpoint,omitempty"`  //Endpoint of the last reply: "rest", "query" or "cache"
+	ServedBy      string        `json:"served_by,omitempty"` //API of the last reply: "rest" or "fallback", empty for pages remembered as missing
+	Attempts      int           `json:"attempts"`
+	Latency       time.Duration `json:"latency_ns"`
+	Error         string        `json:"error,omitempty"`
+}
+
+func newResolution(title string, l lookup, latency time.Duration, err error) Resolution {
+	r := Resolution{Title: title, Endpoint: l.Endpoint, Attempts: l.Attempts, Latency: latency}
+	switch l.Endpoint {
+	case "rest":
+		r.ServedBy = "rest"
+	case "query":
+		r.ServedBy = "fallback"
+	}
+	_, notFound := NotFound(err)
+	switch {
+	case err == nil:
+		r.Status, r.ResolvedTitle = "found", l.Page.Title
+	case notFound:
+		r.Status = "missing"
+	default:
+		r.Status, r.Error = "error", err.Error()
+	}
+	return r
+}
+
+// JSONResolutionLogger returns a resolution observer writing every Resolution to w as a line of JSON.
+func JSONResolutionLogger(w io.Writer) func(Resolution) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	return func(r Resolution) {
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(r)
+	}
+}
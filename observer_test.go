@@ -0,0 +1,42 @@
+package wikipage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestResolutionObserver(t *testing.T) {
+	var buffer bytes.Buffer
+	rh := New("mytest", WithResolutionObserver(JSONResolutionLogger(&buffer)))
+	rh.title2Query = func(title string, life float64) string {
+		return "http://" + address + "?pageids=" + title
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+	for _, title := range []string{"1", "7", "7"} {
+		rh.From(ctx, title)
+	}
+
+	decoder := json.NewDecoder(&buffer)
+	for _, expected := range []Resolution{
+		{Title: "1", ResolvedTitle: "ba", Status: "found", Endpoint: "query", ServedBy: "fallback"},
+		{Title: "7", Status: "missing", Endpoint: "query", ServedBy: "fallback"},
+		{Title: "7", Status: "missing", Endpoint: "cache"},
+	} {
+		var r Resolution
+		if err := decoder.Decode(&r); err != nil {
+			t.Error("Resolution can't be decoded", err)
+			return
+		}
+		if expected.Endpoint != "cache" && r.Attempts < 1 || expected.Endpoint == "cache" && r.Attempts != 0 || r.Latency < 0 {
+			t.Error("Unexpected resolution", r)
+		}
+		r.Attempts, r.Latency = 0, 0
+		if r != expected {
+			t.Error("Resolution is", r, "expected", expected)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package wikipage
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Option customizes a RequestHandler at creation time.
+type Option func(rh *RequestHandler)
+
+// WithMaxConcurrency bounds to n the number of requests a RequestHandler keeps in flight at the same time, independently from the rate limiter. A non positive n means no bound.
+func WithMaxConcurrency(n int) Option {
+	return func(rh *RequestHandler) {
+		rh.semaphore = nil
+		if n > 0 {
+			rh.semaphore = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithMainNamespaceOnly makes From return a WrongNamespace error for every page outside the main (article) namespace.
+func WithMainNamespaceOnly() Option {
+	return func(rh *RequestHandler) {
+		rh.mainNamespaceOnly = true
+	}
+}
+
+// CallOption overrides the RequestHandler defaults for a single call.
+type CallOption func(c *callConfig)
+
+type callConfig struct {
+	lang     string
+	endpoint Endpoint
+	include  Extra
+	compact  bool
+}
+
+// WithLang makes the call target the wiki in the specified language, instead of the handler one, of the handler project.
+func WithLang(lang string) CallOption {
+	return func(c *callConfig) {
+		c.lang = lang
+	}
+}
+
+// ForceFallback makes the call use only the fall back API, instead of the default REST API.
+func ForceFallback() CallOption {
+	return func(c *callConfig) {
+		c.endpoint = EndpointFallback
+	}
+}
+
+// Include makes From retrieve also the specified extras, combined with |, in the same request of the page:
+//
+//	rh.From(ctx, title, Include(ExtraDescription|ExtraImage|ExtraCoordinates|ExtraWikibase))
+//
+// Extras not requested are left empty.
+func Include(extras Extra) CallOption {
+	return func(c *callConfig) {
+		c.include |= extras
+	}
+}
+
+// WithNegativeCacheTTL sets for how long pages found to be missing are remembered, so that further lookups fail without querying the API.
+// By default missing pages are remembered for DefaultNegativeCacheTTL, a non positive ttl disables the cache.
+// Only pages confirmed missing are cached, transient errors never are.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(rh *RequestHandler) {
+		rh.negativeCache = newNegativeCache(ttl)
+	}
+}
+
+// WithHTTPClient makes the RequestHandler issue its requests through client. A client built on DefaultTransport is advised.
+// The client must follow HTTP redirects, as by default: besides host canonicalization, the REST API resolves title redirects through them.
+func WithHTTPClient(client *http.Client) Option {
+	return func(rh *RequestHandler) {
+		rh.client = client
+	}
+}
+
+// WithReplay makes the RequestHandler serve responses recorded in dir, one JSON file for every request keyed by the hash of its method, URL and language negotiation headers, instead of querying the network:
+// it's meant for deterministic tests of code depending on the package. If record is true, requests without a recording are issued and their responses recorded,
+// so that a first run records and the following ones replay; otherwise they fail. Replies other than successful or not found ones are passed on without being recorded,
+// so that transient failures aren't replayed forever. It wraps the transport of the handler client, whatever the order of the options, as well as
+// the one of the handlers derived from it; dir is created on the first recording, if missing.
+func WithReplay(dir string, record bool) Option {
+	return func(rh *RequestHandler) {
+		rh.replay = &replayTransport{dir: dir, record: record}
+	}
+}
+
+// WithClock makes the RequestHandler measure time through clock, mainly useful for testing time based behaviours.
+func WithClock(clock Clock) Option {
+	return func(rh *RequestHandler) {
+		rh.clock = clock
+	}
+}
+
+// WithRequestObserver makes the RequestHandler report to observer the stats of every request it issues, such as the time spent throttled by the rate limiter.
+// The observer may be called concurrently.
+func WithRequestObserver(observer func(RequestStats)) Option {
+	return func(rh *RequestHandler) {
+		rh.requestObserver = observer
+	}
+}
+
+// WithHeader makes the RequestHandler add the specified header to every request, in addition to the User-Agent; it may be repeated.
+// It's useful for wikis requiring authorization or other specific headers, e.g. WithHeader("Authorization", "Bearer "+token).
+func WithHeader(key, value string) Option {
+	return func(rh *RequestHandler) {
+		if rh.headers == nil {
+			rh.headers = http.Header{}
+		}
+		rh.headers.Add(key, value)
+	}
+}
+
+// WithRequestTagger makes the RequestHandler call tagger for every request it issues, with the context of the call, and add the headers it returns,
+// replacing those with the same key: e.g. a tenant ID carried by the context, for a proxy attributing and throttling requests per tenant.
+// tagger may be called concurrently, several times per call because of retries and continuations. Concurrent From calls for the same page
+// share a single lookup, whose requests are tagged from the context of the call that started it.
+func WithRequestTagger(tagger func(ctx context.Context) map[string]string) Option {
+	return func(rh *RequestHandler) {
+		rh.requestTagger = tagger
+	}
+}
+
+// WithLanguage makes the RequestHandler target the wiki in the specified language, of its project, instead of the one it was created for:
+// e.g. through Derive. The WithLang call option does the same for a single call.
+func WithLanguage(lang string) Option {
+	return func(rh *RequestHandler) {
+		rh.lang, rh.baseURL = lang, wikiURL(lang, rh.project)
+	}
+}
+
+// WithProject makes the RequestHandler target the wiki of the specified Wikimedia project, instead of DefaultProject, in the handler language:
+// e.g. New("en", WithProject("wiktionary")) targets en.wiktionary.org. It's meant for the projects sharing the <lang>.<project>.org layout
+// and the APIs of Wikipedia, as "wiktionary", "wikiquote", "wikisource", "wikibooks", "wikinews", "wikiversity" and "wikivoyage".
+func WithProject(project string) Option {
+	return func(rh *RequestHandler) {
+		rh.project, rh.baseURL = project, wikiURL(rh.lang, project)
+	}
+}
+
+// WithBaseURL makes the RequestHandler query the wiki at baseURL, e.g. "http://localhost:8080", instead of the Wikimedia one of its language and project:
+// a mirror, a caching proxy or a fake wiki for tests, as the wikipagetest.NewTestServer one. The REST API and the action API are expected at
+// the standard paths, /api/rest_v1 and /w/api.php. WithProject, as well as the WithLang call option, target the Wikimedia wiki again.
+func WithBaseURL(baseURL string) Option {
+	return func(rh *RequestHandler) {
+		rh.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithVariant makes the RequestHandler request content in the specified language variant, e.g. WithVariant("zh-hans") for Simplified Chinese
+// from the zh Wikipedia, which otherwise replies with its automatic conversion. The variant is negotiated through the Accept-Language header of every request.
+func WithVariant(code string) Option {
+	return func(rh *RequestHandler) {
+		if rh.headers == nil {
+			rh.headers = http.Header{}
+		}
+		rh.headers.Set("Accept-Language", code)
+	}
+}
+
+// WithAttemptTimeout bounds each single attempt of From to timeout, so that a stuck request fails fast and From moves on to the next retry;
+// the context passed to From still bounds the whole call.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(rh *RequestHandler) {
+		rh.attemptTimeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent of every request, instead of DefaultUserAgent or the one set through EnvUserAgent.
+// An empty userAgent leaves the header untouched, so that it can be set at the transport layer: keep in mind that Wikimedia APIs require one.
+func WithUserAgent(userAgent string) Option {
+	return func(rh *RequestHandler) {
+		rh.userAgent = userAgent
+	}
+}
+
+// WithResolutionObserver makes the RequestHandler report to observer a single Resolution at the end of every call to From, e.g. for audit trails through JSONResolutionLogger.
+// The observer may be called concurrently.
+func WithResolutionObserver(observer func(Resolution)) Option {
+	return func(rh *RequestHandler) {
+		rh.resolutionObserver = observer
+	}
+}
+
+// WithSectionFormat sets how section headings are formatted in the plain text returned by the fall back API and FullText:
+// "plain" (the default) flattens them, "wiki" keeps them as "== Heading ==" markers, "raw" keeps them as markers without surrounding whitespace.
+func WithSectionFormat(format string) Option {
+	return func(rh *RequestHandler) {
+		rh.sectionFormat = format
+	}
+}
+
+// WithMobile makes the RequestHandler retrieve HTML formatted for mobile devices, as served to the Wikipedia apps, through the REST mobile-html endpoint.
+// Summaries are the same for all devices, so From isn't affected.
+func WithMobile() Option {
+	return func(rh *RequestHandler) {
+		rh.mobile = true
+	}
+}
+
+// WithCircuitBreaker makes the RequestHandler, and all its copies, stop querying a failing wiki: after the specified number of consecutive failed requests
+// (transport errors, server errors or throttling), calls fail immediately with ErrCircuitOpen, without retrying, for cooldown. Then a single request probes the wiki:
+// on success calls are served again, otherwise they keep failing for another cooldown. A non positive failures disables the breaker, as by default.
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+	return func(rh *RequestHandler) {
+		rh.breaker = newCircuitBreaker(failures, cooldown)
+	}
+}
+
+// WithSentenceTruncation makes From, as well as the batch calls and FromRevision, trim abstracts back to their last complete sentence, so that they don't end with a dangling fragment.
+// Periods of common abbreviations ("Dr.", "e.g.", "U.S.") and decimals ("3.14") aren't taken as the end of a sentence; abstracts without
+// a complete sentence are left as they are. Truncated still reports if the abstract was cut short by the API.
+func WithSentenceTruncation() Option {
+	return func(rh *RequestHandler) {
+		rh.sentenceTruncation = true
+	}
+}
+
+// WithCleanExtract makes From, as well as the batch calls and FromRevision, strip from abstracts the artifacts occasionally left by the plain text extraction, for clean datasets;
+// by default abstracts are returned as the API provides them. In order, it removes:
+//   - leftovers of broken templates: "{{...}}" without nested braces, and then any stray "{{" or "}}";
+//   - reference markers: "[1]", "[a]", "[note 1]", "[nb 1]", "[citation needed]" and "[clarification needed]";
+//   - coordinates in degrees, e.g. "Coordinates: 41°53′24″N 12°29′32″E / 41.89°N 12.49°E / 41.89; 12.49";
+//   - audio markers: "(listen)" and "ⓘ";
+//   - the punctuation left dangling at the start or at the end of parentheses, e.g. "( ; Italian: Roma)", and then the parentheses left empty.
+//
+// Finally runs of spaces, tabs and non breaking spaces are collapsed to a single space, spaces before ",", ".", ";" and ":" or
+// inside the borders of parentheses are removed, lines are trimmed and blank ones are dropped. It's applied before WithSentenceTruncation.
+func WithCleanExtract() Option {
+	return func(rh *RequestHandler) {
+		rh.cleanExtract = true
+	}
+}
+
+// WithMaxAttempts caps to n the number of requests a single call to From issues for a page, regardless of the time budget: the last one queries
+// the fall back API, as usual, and once they are exhausted From returns a BackoffExhausted error. A non positive n means as many as the backoff schedule allows, e.g. 21 in 48 hours.
+func WithMaxAttempts(n int) Option {
+	return func(rh *RequestHandler) {
+		rh.maxAttempts = n
+	}
+}
+
+// WithMaxBackoff bounds to d how long From keeps retrying a page, instead of 48 hours for contexts with a deadline and DefaultMaxBackoff for the ones without:
+// e.g. WithMaxBackoff(48*time.Hour) restores the former unbounded behaviour for contexts without a deadline. An earlier context deadline still applies,
+// a non positive d restores the default.
+func WithMaxBackoff(d time.Duration) Option {
+	return func(rh *RequestHandler) {
+		rh.maxBackoff = d
+	}
+}
+
+// WithMaxBodyBytes caps to n bytes the reply bodies the RequestHandler reads, instead of DefaultMaxBodyBytes, so that memory usage stays bounded
+// whatever the reply: larger ones fail with a ResponseTooLarge error. Unlike the default one, the cap applies to the calls retrieving whole articles too.
+// A non positive n means no cap.
+func WithMaxBodyBytes(n int64) Option {
+	return func(rh *RequestHandler) {
+		rh.maxBodyBytes, rh.customMaxBodyBytes = n, true
+	}
+}
+
+// WithFormatVersion sets the version of the JSON format of the queries From issues to the fall back API, 2 by default: e.g. 1 for older MediaWiki
+// installations supporting only it. Other calls always use version 2.
+func WithFormatVersion(version int) Option {
+	return func(rh *RequestHandler) {
+		rh.formatVersion = version
+	}
+}
+
+// WithStrictTitleMatch makes From reject, with a TitleMismatch error, replies about a page whose title isn't the requested one, nor explained
+// by its normalization or the redirects reported by the API: e.g. the unrelated pages served by rare caching glitches, for high integrity datasets.
+func WithStrictTitleMatch() Option {
+	return func(rh *RequestHandler) {
+		rh.strictTitleMatch = true
+	}
+}
+
+// WithCaseSensitiveTitles tells the RequestHandler that the wiki keeps the case of the first letter of titles, as the ones whose siteinfo
+// reports "case-sensitive" in general.case, e.g. Wiktionary: so that "iPhone" and "IPhone" are told apart as different pages, instead of
+// being compared up to the capitalization of the first letter as on most wikis. See also CaseSensitiveTitles.
+func WithCaseSensitiveTitles() Option {
+	return func(rh *RequestHandler) {
+		rh.caseSensitiveTitles = true
+	}
+}
+
+// WithAdaptiveRate makes the RequestHandler, and all its copies, tune its request rate between min and max requests per second, instead of
+// sharing the fixed rate of DefaultRate with the other handlers: the rate is halved on every reply asking to slow down (too many requests,
+// service unavailable or maxlag errors) and it climbs back by a fiftieth of the range on every other reply. It starts at max, which must be finite;
+// a non positive min means a fiftieth of max.
+func WithAdaptiveRate(min, max rate.Limit) Option {
+	return func(rh *RequestHandler) {
+		rh.adaptive = newAdaptiveRate(min, max)
+		rh.limiter = rh.adaptive.limiter
+	}
+}
+
+// queryBuilders counts the builders set through WithQueryBuilder, to tell them apart.
+var queryBuilders uint64
+
+// WithQueryBuilder makes From build the URL of every attempt through builder, instead of targeting the REST API and the fall back API
+// of the handler wiki: e.g. for exotic MediaWiki setups, or gateways requiring signed URLs. life is the share of the attempts left, from 1
+// down to 0: the standard builder queries the REST summary while it's at least 0.25, then the fall back API. Calls forcing the fall back API,
+// through ForceFallback or FromEndpoint, pass a life of 0, calls forcing the REST API a life of 1. Replies must be shaped as the ones of either API.
+// The builder is used as it is whatever the language, the extras or the base URL of the call, which it's up to builder to honor.
+func WithQueryBuilder(builder func(title string, life float64) (query string)) Option {
+	return func(rh *RequestHandler) {
+		rh.title2Query, rh.customQuery = builder, 0
+		if builder != nil {
+			rh.customQuery = atomic.AddUint64(&queryBuilders, 1)
+		}
+	}
+}
+
+// WithRetryOnEmptyExtract makes From query the fall back API once more when the REST API finds a page but replies with an empty extract,
+// as it happens for some pages or stale cache entries: if the fall back API extracts a non empty abstract of the same page, it replaces the
+// empty one, along with AbstractScope and Truncated, while the rest of the REST summary is kept. It costs an extra request for every such page.
+func WithRetryOnEmptyExtract() Option {
+	return func(rh *RequestHandler) {
+		rh.retryEmptyExtract = true
+	}
+}
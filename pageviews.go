@@ -0,0 +1,105 @@
+package wikipage
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TopPage is one of the most viewed articles of a day.
+type TopPage struct {
+	WikiPage
+	Rank  int    //Position in the ranking of the wiki, from 1, counting the pages which aren't articles as well
+	Views uint64 //Views across all the access methods
+}
+
+// TopViewed returns the limit most viewed articles of the wiki in the day of date, in UTC, as ranked by the Wikimedia pageviews API,
+// resolved to their WikiPages as FromTitles does, with options. Pages which aren't articles, such as "Special:Search", are skipped,
+// as well as those deleted in the meantime; a non positive limit means all the ranked ones, up to a thousand.
+// Ranked pages are resolved 50 at a time, only until limit articles are collected. TopRanking returns the ranking alone, without resolving it.
+// Rankings are published a day or so after the day they refer to, for days not yet published an error is returned.
+func (rh RequestHandler) TopViewed(ctx context.Context, date time.Time, limit int, options ...CallOption) (pages []TopPage, err error) {
+	ranking, err := rh.topViewed(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(ranking) > 0 && (limit <= 0 || len(pages) < limit) {
+		batch := ranking
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		ranking = ranking[len(batch):]
+
+		titles := make([]string, len(batch))
+		for i, p := range batch {
+			titles[i] = p.Title
+		}
+		title2Page, title2Error := rh.FromTitles(ctx, titles, options...)
+		for _, p := range batch {
+			if limit > 0 && len(pages) >= limit {
+				break
+			}
+			if err = title2Error[p.Title]; err != nil {
+				if _, notFound := NotFound(err); !notFound {
+					return nil, err
+				}
+				continue
+			}
+			if p.WikiPage = title2Page[p.Title]; p.Namespace == 0 {
+				pages = append(pages, p)
+			}
+		}
+	}
+	return pages, nil
+}
+
+// TopRanking is like TopViewed, but it returns the limit most viewed pages as ranked, with a single request: only their Title is set,
+// with spaces instead of underscores, and pages which aren't articles, as well as deleted ones, are included.
+func (rh RequestHandler) TopRanking(ctx context.Context, date time.Time, limit int) (ranking []TopPage, err error) {
+	if ranking, err = rh.topViewed(ctx, date); limit > 0 && len(ranking) > limit {
+		ranking = ranking[:limit]
+	}
+	return
+}
+
+// topViewed retrieves the ranking of the most viewed pages of the wiki in the day of date, with only their titles.
+func (rh RequestHandler) topViewed(ctx context.Context, date time.Time) (ranking []TopPage, err error) {
+	day := date.UTC().Format("2006/01/02")
+	query := rh.pageviewsURL + "/metrics/pageviews/top/" + url.PathEscape(rh.lang+"."+rh.project) + "/all-access/" + day
+
+	body, status, err := rh.fetch(ctx, query)
+	switch {
+	case err != nil:
+		//Do nothing
+	case status == http.StatusNotFound:
+		err = errors.Errorf("no ranking published for %v", day)
+	case status != http.StatusOK:
+		err = errors.Errorf("unexpected status %v", status)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error with the following query: %v", query)
+	}
+
+	var data struct {
+		Items []struct {
+			Articles []struct {
+				Article string
+				Views   uint64
+				Rank    int
+			}
+		}
+	}
+	if err = decode(body, &data); err != nil {
+		return nil, errors.Wrapf(err, "error with the following query: %v", query)
+	}
+	for _, item := range data.Items {
+		for _, a := range item.Articles {
+			ranking = append(ranking, TopPage{WikiPage: WikiPage{Title: spaceRule.Replace(a.Article)}, Rank: a.Rank, Views: a.Views})
+		}
+	}
+	return ranking, nil
+}
@@ -0,0 +1,107 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTopViewed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics/pageviews/top/mytest.wikipedia/all-access/2024/01/01":
+			fmt.Fprint(w, `{"items":[{"project":"mytest.wikipedia","access":"all-access","year":"2024","month":"01","day":"01","articles":[`+
+				`{"article":"Main_Page","views":5000,"rank":1},{"article":"Special:Search","views":3000,"rank":2},`+
+				`{"article":"Deleted","views":2000,"rank":3},{"article":"New_Year's_Day","views":1000,"rank":4},{"article":"Rome","views":500,"rank":5}]}]}`)
+		case "/w/api.php":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":-1,"title":"Special:Search","special":true},{"ns":0,"title":"Deleted","missing":true},`+
+				`{"pageid":1,"ns":0,"title":"Main Page","extract":"Welcome."},{"pageid":2,"ns":0,"title":"New Year's Day","extract":"A holiday."},`+
+				`{"pageid":3,"ns":0,"title":"Rome","extract":"A city."}]}}`)
+		default:
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL, rh.pageviewsURL = server.URL, server.URL
+	date := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for limit, expected := range map[int][]string{0: {"Main Page", "New Year's Day", "Rome"}, 2: {"Main Page", "New Year's Day"}} {
+		pages, err := rh.TopViewed(context.Background(), date, limit)
+		var titles []string
+		for _, p := range pages {
+			titles = append(titles, p.Title)
+		}
+		if err != nil || !reflect.DeepEqual(titles, expected) {
+			t.Error("TopViewed with limit", limit, "returns", titles, err, "expected", expected)
+		}
+		if len(pages) > 1 && (pages[1].Rank != 4 || pages[1].Views != 1000 || pages[1].ID != 2 || pages[1].Abstract != "A holiday.") {
+			t.Error("TopViewed returns", pages[1], "expected New Year's Day with its summary, rank and views")
+		}
+	}
+
+	if _, err := rh.TopViewed(context.Background(), date.AddDate(0, 0, 1), 0); err == nil {
+		t.Error("TopViewed should fail for days not yet published")
+	}
+}
+
+func TestTopViewedBatches(t *testing.T) {
+	var articles []string
+	for i := 1; i <= 120; i++ {
+		articles = append(articles, fmt.Sprintf(`{"article":"Page_%v","views":%v,"rank":%v}`, i, 1000-i, i))
+	}
+	var queries, resolved int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics/pageviews/top/mytest.wikipedia/all-access/2024/01/01":
+			fmt.Fprint(w, `{"items":[{"articles":[`+strings.Join(articles, ",")+`]}]}`)
+		case "/w/api.php":
+			queries++
+			var pages []string
+			for _, title := range strings.Split(r.URL.Query().Get("titles"), "|") {
+				resolved++
+				var ID int
+				fmt.Sscanf(title, "Page %d", &ID)
+				pages = append(pages, fmt.Sprintf(`{"pageid":%v,"ns":0,"title":%q,"extract":"A page."}`, ID, title))
+			}
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[`+strings.Join(pages, ",")+`]}}`)
+		default:
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL, rh.pageviewsURL = server.URL, server.URL
+	date := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	pages, err := rh.TopViewed(context.Background(), date, 10)
+	switch {
+	case err != nil || len(pages) != 10 || pages[9].Title != "Page 10" || pages[9].ID != 10:
+		t.Error("TopViewed returns", len(pages), "pages,", err)
+	case queries != 1 || resolved != batchSize:
+		t.Error("TopViewed should resolve a single batch for the first ten pages, instead it issued", queries, "queries for", resolved, "titles")
+	}
+
+	queries, resolved = 0, 0
+	if pages, err = rh.TopViewed(context.Background(), date, 0); err != nil || len(pages) != 120 || queries != 3 {
+		t.Error("TopViewed returns", len(pages), "pages,", err, "with", queries, "queries")
+	}
+
+	queries = 0
+	ranking, err := rh.TopRanking(context.Background(), date, 10)
+	switch {
+	case err != nil || len(ranking) != 10:
+		t.Error("TopRanking returns", len(ranking), "pages,", err)
+	case !reflect.DeepEqual(ranking[0], TopPage{WikiPage: WikiPage{Title: "Page 1"}, Rank: 1, Views: 999}):
+		t.Error("TopRanking returns", ranking[0])
+	case queries != 0:
+		t.Error("TopRanking shouldn't resolve pages, instead it issued", queries, "queries")
+	}
+}
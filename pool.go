@@ -0,0 +1,50 @@
+package wikipage
+
+import (
+	"sync"
+)
+
+// HandlerPool lazily creates, and then reuses, a RequestHandler for every language, all customized with the same options. It's safe to use concurrently.
+type HandlerPool struct {
+	options []Option
+
+	mutex        sync.Mutex
+	lang2Handler map[string]RequestHandler
+}
+
+// NewHandlerPool returns an empty HandlerPool, whose handlers are customized through options.
+func NewHandlerPool(options ...Option) *HandlerPool {
+	return &HandlerPool{options: options, lang2Handler: map[string]RequestHandler{}}
+}
+
+// Get returns the RequestHandler for the specified language, creating it on first use: later calls return the same handler,
+// sharing its state such as the negative cache. Every language has its own HTTP client and rate limiter, as wikis are rate limited
+// on their own, unless the pool options set them, e.g. through WithHTTPClient.
+func (pool *HandlerPool) Get(lang string) RequestHandler {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	rh, ok := pool.lang2Handler[lang]
+	if !ok {
+		rh = New(lang, append([]Option{withOwnClientAndLimiter()}, pool.options...)...)
+		pool.lang2Handler[lang] = rh
+	}
+	return rh
+}
+
+// withOwnClientAndLimiter makes the RequestHandler use a new client and limiter, instead of the shared ones.
+func withOwnClientAndLimiter() Option {
+	return func(rh *RequestHandler) {
+		rh.client, rh.limiter = newClientAndLimiter()
+	}
+}
+
+// Close closes all the handlers of the pool, as RequestHandler.Close does, and empties it.
+func (pool *HandlerPool) Close() error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	for lang, rh := range pool.lang2Handler {
+		rh.Close()
+		delete(pool.lang2Handler, lang)
+	}
+	return nil
+}
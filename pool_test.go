@@ -0,0 +1,47 @@
+package wikipage
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestHandlerPool(t *testing.T) {
+	pool := NewHandlerPool(WithProject("wiktionary"))
+
+	var wg sync.WaitGroup
+	handlers := make([]RequestHandler, 8)
+	for i := range handlers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			handlers[i] = pool.Get("it")
+		}(i)
+	}
+	wg.Wait()
+	for _, rh := range handlers {
+		if rh.negativeCache != handlers[0].negativeCache || rh.baseURL != "https://it.wiktionary.org" {
+			t.Error("Get should return the same handler for the same language, with the pool options")
+		}
+	}
+	if rh := pool.Get("de"); rh.negativeCache == handlers[0].negativeCache || rh.baseURL != "https://de.wiktionary.org" {
+		t.Error("Get should return a different handler for every language")
+	}
+	if rh := pool.Get("de"); rh.limiter == handlers[0].limiter || rh.client == handlers[0].client || rh.limiter == New("it").limiter {
+		t.Error("Get should return handlers with their own client and limiter for every language")
+	}
+
+	client := &http.Client{}
+	explicit := NewHandlerPool(WithHTTPClient(client))
+	if rh := explicit.Get("it"); rh.client != client {
+		t.Error("Get should return handlers with the client of the pool options, if any")
+	}
+
+	pool.Close()
+	if !handlers[0].closer.Closed() {
+		t.Error("Close should close the handlers of the pool")
+	}
+	if rh := pool.Get("it"); rh.closer.Closed() {
+		t.Error("Get should return a new handler after Close")
+	}
+}
@@ -0,0 +1,171 @@
+package wikipage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// BatchProcessor retrieves large sets of pages by ID in resumable jobs: the IDs processed are appended to a checkpoint, one decimal ID per line,
+// and skipped when the job is resumed from it. Pages are retrieved as FromIDs does, a batch of 50 IDs per query, with bounded concurrency
+// on top of the handler rate limiter.
+type BatchProcessor struct {
+	rh          RequestHandler
+	concurrency int
+
+	mutex      sync.Mutex
+	done       *roaring.Bitmap
+	checkpoint io.Writer
+}
+
+// NewBatchProcessor returns a BatchProcessor querying through rh at most concurrency batches at the same time, appending the IDs processed to checkpoint.
+// If resume isn't nil, the IDs it lists, as written to a previous checkpoint, are considered processed already: a truncated last line,
+// as left by a crash, is ignored.
+func NewBatchProcessor(rh RequestHandler, concurrency int, resume io.Reader, checkpoint io.Writer) (*BatchProcessor, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	bp := &BatchProcessor{rh: rh, concurrency: concurrency, done: roaring.New(), checkpoint: checkpoint}
+	if resume == nil {
+		return bp, nil
+	}
+
+	reader := bufio.NewReader(resume)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return bp, nil //The last line, if any, lacks the newline: it's truncated
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ID, err := strconv.ParseUint(strings.TrimSpace(line), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid checkpoint line %q", line)
+		}
+		bp.done.Add(uint32(ID))
+	}
+}
+
+// Done returns a copy of the IDs processed so far, resumed ones included.
+func (bp *BatchProcessor) Done() *roaring.Bitmap {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	return bp.done.Clone()
+}
+
+// Run retrieves the pages with the IDs received from IDs until it's closed, skipping the ones processed already, and calls sink for each of them,
+// with either its page or its error; sink isn't called concurrently. IDs are checkpointed once sink returns for them, unless their error
+// is transient, so that they're retried on resume: pages confirmed missing are processed as well.
+// Run stops at the first error returned by sink, or by the checkpoint writer, and returns it, without waiting for IDs to be closed.
+func (bp *BatchProcessor) Run(ctx context.Context, IDs <-chan PageID, sink func(ID PageID, p WikiPage, err error) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []PageID)
+	go func() { //Stops on cancel as well, so that it doesn't outlive Run while IDs stay open
+		defer close(batches)
+		batch := make([]PageID, 0, batchSize)
+		for {
+			var ID PageID
+			var ok bool
+			select {
+			case ID, ok = <-IDs:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				break
+			}
+			if bp.isDone(ID) {
+				continue
+			}
+			if batch = append(batch, ID); len(batch) < batchSize {
+				continue
+			}
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return
+			}
+			batch = make([]PageID, 0, batchSize)
+		}
+		if len(batch) > 0 {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	var runErr error
+	var once sync.Once
+	fail := func(err error) {
+		once.Do(func() {
+			runErr = err
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < bp.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := bp.process(ctx, batch, sink); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if runErr == nil {
+		runErr = ctx.Err()
+	}
+	return runErr
+}
+
+// isDone checks if ID was processed already.
+func (bp *BatchProcessor) isDone(ID PageID) bool {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	return bp.done.Contains(uint32(ID))
+}
+
+// process retrieves a batch of IDs, passing the results to sink and checkpointing the IDs processed.
+func (bp *BatchProcessor) process(ctx context.Context, batch []PageID, sink func(ID PageID, p WikiPage, err error) error) error {
+	ID2Page, ID2Error := bp.rh.FromIDs(ctx, batch)
+	if ctx.Err() != nil {
+		return nil //Either the job was stopped or the context cancelled, the batch will be processed on resume
+	}
+
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+
+	var lines strings.Builder
+	var sinkErr error
+	for _, ID := range batch {
+		p, err := ID2Page[ID], ID2Error[ID]
+		if sinkErr = sink(ID, p, err); sinkErr != nil {
+			break
+		}
+		if _, notFound := NotFound(err); err == nil || notFound {
+			bp.done.Add(uint32(ID))
+			fmt.Fprintln(&lines, ID)
+		}
+	}
+
+	if _, err := io.WriteString(bp.checkpoint, lines.String()); err != nil {
+		return errors.WithStack(err)
+	}
+	return sinkErr
+}
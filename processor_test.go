@@ -0,0 +1,119 @@
+package wikipage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBatchProcessor(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	const n = 120
+	IDs := func() <-chan PageID {
+		ch := make(chan PageID)
+		go func() {
+			defer close(ch)
+			for ID := PageID(1); ID <= n; ID++ {
+				ch <- ID
+			}
+		}()
+		return ch
+	}
+
+	//A job interrupted by its sink
+	var checkpoint bytes.Buffer
+	bp, err := NewBatchProcessor(rh, 3, nil, &checkpoint)
+	if err != nil {
+		t.Fatal("NewBatchProcessor returns", err)
+	}
+	stop := errors.New("stop")
+	var sunk int
+	err = bp.Run(context.Background(), IDs(), func(ID PageID, p WikiPage, err error) error {
+		if sunk++; sunk > 60 {
+			return stop
+		}
+		return nil
+	})
+	if errors.Cause(err) != stop {
+		t.Error("Run should return the error of the sink, instead it returns", err)
+	}
+	interrupted := bp.Done().GetCardinality()
+	if interrupted == 0 || interrupted >= n {
+		t.Error("Run processed", interrupted, "IDs before being stopped")
+	}
+
+	//Its resume, from a checkpoint truncated by a crash
+	resume := checkpoint.String() + "12"
+	checkpoint.Reset()
+	if bp, err = NewBatchProcessor(rh, 3, strings.NewReader(resume), &checkpoint); err != nil {
+		t.Fatal("NewBatchProcessor returns", err)
+	}
+	err = bp.Run(context.Background(), IDs(), func(ID PageID, p WikiPage, err error) error {
+		expected, ok := generatePage(uint32(ID))
+		_, notFound := NotFound(err)
+		switch {
+		case !ok && !notFound, ok && (err != nil || p.Title != expected.Title):
+			t.Error("For", ID, "sink receives", p, err)
+		case strings.Contains(resume, "\n"+ID.String()+"\n") || strings.HasPrefix(resume, ID.String()+"\n"):
+			t.Error("ID", ID, "processed twice")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error("Run returns", err)
+	}
+	if processed := bp.Done().GetCardinality(); processed != n {
+		t.Error("Run processed", processed, "IDs out of", n)
+	}
+	if lines := strings.Count(checkpoint.String(), "\n"); uint64(lines) != n-interrupted {
+		t.Error("Checkpoint has", lines, "lines, expected", n-interrupted)
+	}
+}
+
+func TestBatchProcessorOpenIDs(t *testing.T) {
+	server := batchServer(t)
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	bp, err := NewBatchProcessor(rh, 2, nil, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal("NewBatchProcessor returns", err)
+	}
+
+	//IDs is left open, as by a producer waiting for the job to end
+	IDs := make(chan PageID)
+	stop := errors.New("stop")
+	result := make(chan error, 1)
+	go func() {
+		result <- bp.Run(context.Background(), IDs, func(ID PageID, p WikiPage, err error) error {
+			return stop
+		})
+	}()
+	for ID := PageID(1); ID <= batchSize; ID++ {
+		IDs <- ID
+	}
+
+	select {
+	case err := <-result:
+		if errors.Cause(err) != stop {
+			t.Error("Run should return the error of the sink, instead it returns", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run hangs after the sink fails while IDs is open")
+	}
+	select {
+	case IDs <- batchSize + 1:
+		t.Error("Run returned leaving behind a goroutine receiving from IDs")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,47 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Protection returns the protection of the article with the specified title, following redirects, mapping every protected action
+// (e.g. "edit" or "move") to the level required to perform it (e.g. "autoconfirmed" or "sysop"). Unprotected articles have an empty map.
+func (rh RequestHandler) Protection(ctx context.Context, title string) (action2Level map[string]string, err error) {
+	query := rh.apiQuery(url.Values{
+		"action":    {"query"},
+		"prop":      {"info"},
+		"inprop":    {"protection"},
+		"redirects": {""},
+		"titles":    {title},
+	})
+
+	var data struct {
+		Query struct {
+			Pages []struct {
+				Missing    bool
+				Protection []struct {
+					Type, Level string
+				}
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing {
+		return nil, errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	action2Level = map[string]string{}
+	for _, p := range data.Query.Pages[0].Protection {
+		action2Level[p.Type] = p.Level
+	}
+	return
+}
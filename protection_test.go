@@ -0,0 +1,37 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("titles") {
+		case "Barack Obama":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":534366,"ns":0,"title":"Barack Obama","contentmodel":"wikitext","length":349823,"protection":[{"type":"edit","level":"autoconfirmed","expiry":"infinity"},{"type":"move","level":"sysop","expiry":"infinity"}],"restrictiontypes":["edit","move"]}]}}`)
+		case "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","contentmodel":"wikitext","length":213475,"protection":[],"restrictiontypes":["edit","move"]}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Atlantis","missing":true,"protection":[]}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	for title, expected := range map[string]map[string]string{"Barack Obama": {"edit": "autoconfirmed", "move": "sysop"}, "Anarchism": {}} {
+		if action2Level, err := rh.Protection(context.Background(), title); err != nil || !reflect.DeepEqual(action2Level, expected) {
+			t.Error("Protection of", title, "returns", action2Level, err, "expected", expected)
+		}
+	}
+	if _, err := rh.Protection(context.Background(), "Atlantis"); err == nil {
+		t.Error("Protection should fail on a missing page")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("Protection returns", err, "expected not found")
+	}
+}
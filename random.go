@@ -0,0 +1,77 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// randomLimit is the maximum number of random pages the action API returns in a single query.
+const randomLimit = 500
+
+// RandomN returns n distinct random articles (main namespace, redirects excluded), resolved to their summaries through FromIDs.
+// It issues as many list=random queries as needed, discarding the pages the API repeats across them and those deleted in the meantime;
+// fewer than n articles are returned only if the wiki runs short of them.
+func (rh RequestHandler) RandomN(ctx context.Context, n int) (pages []WikiPage, err error) {
+	seen := map[PageID]bool{}
+	for len(pages) < n {
+		var IDs []PageID
+		if IDs, err = rh.randomIDs(ctx, n-len(pages), seen); err != nil {
+			return nil, err
+		}
+		if len(IDs) == 0 {
+			break //No new article in a whole reply, the wiki is exhausted
+		}
+
+		ID2Page, ID2Error := rh.FromIDs(ctx, IDs)
+		for _, ID := range IDs {
+			if err = ID2Error[ID]; err != nil {
+				if _, notFound := NotFound(err); !notFound {
+					return nil, err
+				}
+				continue
+			}
+			pages = append(pages, ID2Page[ID])
+		}
+	}
+	return pages, nil
+}
+
+// randomIDs queries for up to n random articles, returning the IDs not in seen and adding them to it.
+func (rh RequestHandler) randomIDs(ctx context.Context, n int, seen map[PageID]bool) (IDs []PageID, err error) {
+	if n > randomLimit {
+		n = randomLimit
+	}
+	query := rh.apiQuery(url.Values{
+		"action":        {"query"},
+		"list":          {"random"},
+		"rnnamespace":   {"0"},
+		"rnfilterredir": {"nonredirects"},
+		"rnlimit":       {strconv.Itoa(n)},
+	})
+
+	var data struct {
+		Query struct {
+			Random []struct {
+				ID PageID
+				NS int
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return nil, err
+	}
+	if err = data.asError("random"); err != nil {
+		return nil, err
+	}
+
+	for _, p := range data.Query.Random {
+		if p.NS != 0 || seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		IDs = append(IDs, p.ID)
+	}
+	return IDs, nil
+}
@@ -0,0 +1,72 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRandomN(t *testing.T) {
+	const wikiSize = 12
+	type randomPage struct {
+		ID PageID `json:"id"`
+		NS int    `json:"ns"`
+	}
+	var next int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("list") != "random" {
+			var response struct {
+				Query struct{ Pages []mayMissingPage }
+			}
+			for _, sID := range strings.Split(q.Get("pageids"), "|") {
+				ID, _ := strconv.ParseUint(sID, 10, 32)
+				p, ok := generatePage(uint32(ID))
+				response.Query.Pages = append(response.Query.Pages, mayMissingPage{Missing: !ok, WikiPage: p})
+			}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		limit, _ := strconv.Atoi(q.Get("rnlimit"))
+		if limit < 1 || limit > randomLimit {
+			t.Error("Unexpected rnlimit", limit)
+		}
+		var response struct {
+			Query struct {
+				Random []randomPage
+			}
+		}
+		//Pages cycle, so that they repeat across replies, and are followed by a talk page
+		for i := 0; i < limit; i++ {
+			response.Query.Random = append(response.Query.Random, randomPage{PageID(next%wikiSize + 1), 0})
+			next++
+		}
+		response.Query.Random = append(response.Query.Random, randomPage{100, 1})
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	for n, expected := range map[int]int{0: 0, 5: 5, 10: 10, 20: wikiSize - 1} {
+		next = 0
+		pages, err := rh.RandomN(context.Background(), n)
+		if err != nil || len(pages) != expected {
+			t.Error("RandomN(", n, ") returns", len(pages), "pages and", err, "expected", expected, "pages")
+		}
+		seen := map[PageID]bool{}
+		for _, p := range pages {
+			if expected, ok := generatePage(uint32(p.ID)); !ok || seen[p.ID] || !reflect.DeepEqual(p, expected) {
+				t.Error("RandomN(", n, ") returns unexpected page", p)
+			}
+			seen[p.ID] = true
+		}
+	}
+}
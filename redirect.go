@@ -0,0 +1,90 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxRedirectHops is the maximum length of a redirect chain walked by RedirectChain.
+const maxRedirectHops = 32
+
+// RedirectChain walks the redirects starting from the article with the specified title, returning every title from the (normalized)
+// requested one up to the final target. The wiki resolves chains of redirects in a single reply, listing every hop, as far as it can:
+// the walk goes on with further queries otherwise. A RedirectLoop error is returned if a page redirects back to an earlier one.
+func (rh RequestHandler) RedirectChain(ctx context.Context, title string) (chain []string, err error) {
+	seen := map[string]bool{}
+	for current := title; len(chain) < maxRedirectHops; {
+		query := rh.apiQuery(url.Values{
+			"action":    {"query"},
+			"prop":      {"info"},
+			"redirects": {""},
+			"titles":    {current},
+		})
+
+		var data struct {
+			Query struct {
+				Normalized []struct{ From, To string }
+				Redirects  []struct{ From, To string }
+				Pages      []struct {
+					Title             string
+					Missing, Redirect bool
+				}
+			}
+			apiErrors
+		}
+		if err = rh.getJSON(ctx, query, &data); err != nil {
+			return nil, err
+		}
+		if err = data.asError(current); err != nil {
+			return nil, err
+		}
+
+		if len(chain) == 0 {
+			for _, n := range data.Query.Normalized {
+				current = n.To
+			}
+			chain, seen[current] = append(chain, current), true
+		}
+
+		if len(data.Query.Redirects) == 0 {
+			if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing {
+				return nil, errors.WithStack(pageNotFound{title: current, endpoint: "query"})
+			}
+			return chain, nil
+		}
+
+		walked, hops := len(chain), make(map[string]string, len(data.Query.Redirects))
+		for _, r := range data.Query.Redirects {
+			hops[r.From] = r.To
+		}
+		for to, ok := hops[current]; ok; to, ok = hops[current] {
+			current = to
+			switch {
+			case seen[current]:
+				return nil, errors.WithStack(RedirectLoop{append(chain, current)})
+			case len(chain) == maxRedirectHops:
+				return nil, errors.Errorf("redirect chain from %v exceeds %v hops", title, maxRedirectHops)
+			}
+			chain, seen[current] = append(chain, current), true
+		}
+
+		if len(data.Query.Pages) == 0 || !data.Query.Pages[0].Redirect || len(chain) == walked { //No further hops, or no progress
+			return chain, nil
+		}
+	}
+
+	return nil, errors.Errorf("redirect chain from %v exceeds %v hops", title, maxRedirectHops)
+}
+
+// RedirectLoop is the error returned for redirect chains where a page redirects back to an earlier one.
+type RedirectLoop struct {
+	Chain []string
+}
+
+func (err RedirectLoop) Error() string {
+	return fmt.Sprintf("redirect loop: %v", strings.Join(err.Chain, " -> "))
+}
@@ -0,0 +1,108 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRedirectChain(t *testing.T) {
+	from2To := map[string]string{
+		"USA":           "U.S.A.",
+		"U.S.A.":        "United States",
+		"Loop":          "Loop again",
+		"Loop again":    "Loop",
+		"United States": "",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		title := r.URL.Query().Get("titles")
+		type fromTo struct{ From, To string }
+		type page struct {
+			Title             string
+			Missing, Redirect bool
+		}
+		var response struct {
+			Query struct {
+				Normalized []fromTo
+				Redirects  []fromTo
+				Pages      []page
+			}
+		}
+		if title == "usa" {
+			response.Query.Normalized = []fromTo{{"usa", "USA"}}
+			title = "USA"
+		}
+		to, ok := from2To[title]
+		switch {
+		case !ok:
+			response.Query.Pages = []page{{Title: title, Missing: true}}
+		case to == "":
+			response.Query.Pages = []page{{Title: title}}
+		default:
+			response.Query.Redirects = []fromTo{{title, to}}
+			target, targetExists := from2To[to]
+			response.Query.Pages = []page{{Title: to, Missing: !targetExists, Redirect: target != ""}}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	chain, err := rh.RedirectChain(context.Background(), "usa")
+	expected := []string{"USA", "U.S.A.", "United States"}
+	switch {
+	case err != nil:
+		t.Error("RedirectChain returns ", err)
+	case !reflect.DeepEqual(chain, expected):
+		t.Error("RedirectChain returns", chain, "expected", expected)
+	}
+
+	if chain, err = rh.RedirectChain(context.Background(), "United States"); err != nil || !reflect.DeepEqual(chain, []string{"United States"}) {
+		t.Error("RedirectChain returns", chain, err)
+	}
+
+	_, err = rh.RedirectChain(context.Background(), "Loop")
+	if loop, ok := errors.Cause(err).(RedirectLoop); !ok || !reflect.DeepEqual(loop.Chain, []string{"Loop", "Loop again", "Loop"}) {
+		t.Error("RedirectChain should return a RedirectLoop error, instead it returns", err)
+	}
+
+	if _, err = rh.RedirectChain(context.Background(), "0test1test2test3"); err == nil {
+		t.Error("RedirectChain should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("RedirectChain returns an unexpected error", err)
+	}
+}
+
+func TestRedirectChainMultiHop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("titles") {
+		case "USA": //The wiki resolves double redirects in a single reply
+			fmt.Fprint(w, `{"query":{"redirects":[{"from":"USA","to":"U.S.A."},{"from":"U.S.A.","to":"United States"}],"pages":[{"title":"United States"}]}}`)
+		case "Loop":
+			fmt.Fprint(w, `{"query":{"redirects":[{"from":"Loop","to":"Loop again"},{"from":"Loop again","to":"Loop once more"},{"from":"Loop once more","to":"Loop again"}],`+
+				`"pages":[{"title":"Loop again","redirect":true}]}}`)
+		default:
+			t.Error("Unexpected query", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithBaseURL(server.URL))
+	chain, err := rh.RedirectChain(context.Background(), "USA")
+	if expected := []string{"USA", "U.S.A.", "United States"}; err != nil || !reflect.DeepEqual(chain, expected) {
+		t.Error("RedirectChain returns", chain, err, "expected", expected)
+	}
+
+	_, err = rh.RedirectChain(context.Background(), "Loop")
+	if loop, ok := errors.Cause(err).(RedirectLoop); !ok || !reflect.DeepEqual(loop.Chain, []string{"Loop", "Loop again", "Loop once more", "Loop again"}) {
+		t.Error("RedirectChain should detect loops through intermediate titles, instead it returns", err)
+	}
+}
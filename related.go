@@ -0,0 +1,19 @@
+package wikipage
+
+import "context"
+
+// Related returns the summaries of the articles related to the one with the specified title.
+func (rh RequestHandler) Related(ctx context.Context, title string) (pages []WikiPage, err error) {
+	var data struct {
+		Pages []restSummary
+	}
+	if err = rh.getREST(ctx, title, rh.restQuery("related", title), &data); err != nil {
+		return
+	}
+
+	pages = make([]WikiPage, len(data.Pages))
+	for i, s := range data.Pages {
+		pages[i] = s.page()
+	}
+	return
+}
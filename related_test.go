@@ -0,0 +1,47 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRelated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/rest_v1/page/related/Anarchism":
+			fmt.Fprint(w, `{"pages":[
+				{"type":"standard","title":"Libertarian_socialism","titles":{"canonical":"Libertarian_socialism","normalized":"Libertarian socialism","display":"Libertarian <i>socialism</i>"},"namespace":{"id":0,"text":""},"pageid":18,"extract":"Libertarian socialism is a political philosophy."},
+				{"type":"standard","title":"Mutualism","namespace":{"id":0,"text":""},"pageid":19,"extract":"Mutualism is an economic theory."}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found","title":"Not found."}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	pages, err := rh.Related(context.Background(), "Anarchism")
+	expected := []WikiPage{
+		{ID: 18, Title: "Libertarian_socialism", Abstract: "Libertarian socialism is a political philosophy.", AbstractScope: "summary", DisplayTitle: "Libertarian <i>socialism</i>"},
+		{ID: 19, Title: "Mutualism", Abstract: "Mutualism is an economic theory.", AbstractScope: "summary", DisplayTitle: "Mutualism"},
+	}
+	switch {
+	case err != nil:
+		t.Error("Related returns ", err)
+	case !reflect.DeepEqual(pages, expected):
+		t.Error("Related returns", pages, "expected", expected)
+	}
+
+	if _, err = rh.Related(context.Background(), "0test1test2test3"); err == nil {
+		t.Error("Related should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("Related returns an unexpected error", err)
+	}
+}
@@ -0,0 +1,133 @@
+package wikipage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// replayTransport serves responses recorded in a directory, one JSON file for every request keyed by the hash of its method, URL and replayHeaders.
+type replayTransport struct {
+	dir    string
+	record bool              //Record missing responses, instead of failing
+	next   http.RoundTripper //Transport issuing the requests to record
+}
+
+// replayClient returns the client of the handler with its transport wrapped as set through WithReplay, replacing any previous replay wrapper.
+func (rh RequestHandler) replayClient() *http.Client {
+	if rh.replay == nil {
+		return rh.client
+	}
+	next := rh.client.Transport
+	if t, ok := next.(replayTransport); ok {
+		if t.dir == rh.replay.dir && t.record == rh.replay.record {
+			return rh.client
+		}
+		next = t.next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client := *rh.client
+	client.Transport = replayTransport{rh.replay.dir, rh.replay.record, next}
+	return &client
+}
+
+// recording is a response as stored by replayTransport.
+type recording struct {
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// replayHeaders are the request headers affecting the response, which recordings are keyed by too, when set.
+var replayHeaders = []string{"Accept-Language"}
+
+func (t replayTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	request := r.Method + " " + r.URL.String()
+	for _, name := range replayHeaders {
+		if value := r.Header.Get(name); value != "" {
+			request += "\n" + name + ": " + value
+		}
+	}
+	key := sha256.Sum256([]byte(request))
+	path := filepath.Join(t.dir, hex.EncodeToString(key[:])+".json")
+
+	var rec recording
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(data, &rec); err != nil {
+			return nil, errors.Wrapf(err, "invalid recording %v", path)
+		}
+	case !os.IsNotExist(err):
+		return nil, errors.WithStack(err)
+	case !t.record:
+		return nil, errors.Errorf("no recording of %v %v", r.Method, r.URL)
+	default:
+		if rec, err = t.recordTo(path, r); err != nil {
+			return nil, err
+		}
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(rec.Status),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       r,
+	}, nil
+}
+
+// recordTo issues the request and stores its response in path, unless it's a transient failure: only successful and not found replies are recorded,
+// so that a later run recording again may get over the failure.
+func (t replayTransport) recordTo(path string, r *http.Request) (rec recording, err error) {
+	response, err := t.next.RoundTrip(r)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return rec, errors.WithStack(err)
+	}
+	rec = recording{r.URL.String(), response.StatusCode, response.Header, string(body)}
+	if (response.StatusCode < 200 || response.StatusCode > 299) && response.StatusCode != http.StatusNotFound {
+		return rec, nil
+	}
+
+	data, err := json.MarshalIndent(rec, "", "\t")
+	if err != nil {
+		return rec, errors.WithStack(err)
+	}
+	if err = os.MkdirAll(t.dir, 0755); err != nil {
+		return rec, errors.WithStack(err)
+	}
+	//Write and rename, so that concurrent replays never see partial recordings
+	tmp, err := ioutil.TempFile(t.dir, ".recording")
+	if err != nil {
+		return rec, errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	return rec, errors.WithStack(err)
+}
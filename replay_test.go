@@ -0,0 +1,124 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, record := range []bool{true, true, false} {
+		rh := New("mytest", WithNegativeCacheTTL(0), WithMaxAttempts(1), WithReplay(dir, record))
+		rh.baseURL = server.URL
+		rh.title2Query = defaultTitle2Query(rh)
+		if p, err := rh.From(context.Background(), "Anarchism"); err != nil || p.Abstract != "Anarchism is a political philosophy." {
+			t.Error("Run", i, "From returns", p, err)
+		}
+	}
+	if requests != 1 {
+		t.Error("Only the first run should issue requests, instead", requests, "were issued")
+	}
+
+	rh := New("mytest", WithMaxAttempts(1), WithReplay(dir, false))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if _, err := rh.From(context.Background(), "Autism"); err == nil || requests != 1 {
+		t.Error("Requests without a recording should fail, instead From returns", err)
+	}
+}
+
+func TestReplayTransientFailures(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case requests == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Header.Get("Accept-Language") == "zh-hans":
+			fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"简体"}`)
+		default:
+			fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"繁體"}`)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	from := func(record bool, options ...Option) (WikiPage, error) {
+		rh := New("mytest", append([]Option{WithNegativeCacheTTL(0), WithMaxAttempts(1), WithBaseURL(server.URL), WithReplay(dir, record)}, options...)...)
+		return rh.From(context.Background(), "Anarchism")
+	}
+	if _, err := from(true); err == nil {
+		t.Error("From should fail on the transient failure")
+	}
+	if p, err := from(true); err != nil || p.Abstract != "繁體" {
+		t.Error("Transient failures shouldn't be recorded, instead From returns", p, err)
+	}
+	if p, err := from(true, WithVariant("zh-hans")); err != nil || p.Abstract != "简体" {
+		t.Error("Recordings should be told apart by language variant, instead From returns", p, err)
+	}
+	for variant, expected := range map[string]string{"": "繁體", "zh-hans": "简体"} {
+		var options []Option
+		if variant != "" {
+			options = append(options, WithVariant(variant))
+		}
+		if p, err := from(false, options...); err != nil || p.Abstract != expected {
+			t.Error("Replaying variant", variant, "From returns", p, err, "expected", expected)
+		}
+	}
+	if requests != 3 {
+		t.Error("Replays shouldn't issue requests, instead", requests, "were issued")
+	}
+}
+
+func TestReplayOptionOrder(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+	}))
+	defer server.Close()
+
+	parent, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(parent)
+	dir := filepath.Join(parent, "recordings", "wikipage") //Missing, to be created on the first recording
+
+	client := &http.Client{}
+	base := New("mytest", WithNegativeCacheTTL(0), WithMaxAttempts(1), WithBaseURL(server.URL), WithReplay(dir, true), WithHTTPClient(client))
+	for i, rh := range []RequestHandler{base, base.Derive(WithHTTPClient(client)), base.Derive(WithReplay(dir, false))} {
+		if p, err := rh.From(context.Background(), "Anarchism"); err != nil || p.Abstract != "Anarchism is a political philosophy." {
+			t.Error("Handler", i, "From returns", p, err)
+		}
+	}
+	if requests != 1 {
+		t.Error("Replay should apply whatever the order of the options, instead", requests, "requests were issued")
+	}
+	if client.Transport != nil {
+		t.Error("WithReplay shouldn't alter the client passed to WithHTTPClient")
+	}
+}
@@ -0,0 +1,109 @@
+package wikipage
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FromRevision returns the WikiPage of the article with the specified title, following redirects, as it was at the specified time:
+// it looks up the revision active at that time and then retrieves it. Since the fall back API extracts only current revisions, the abstract
+// is rebuilt from the paragraphs of the rendered lead section, so it may slightly differ from the one returned by From for the same revision.
+// Articles created after the specified time are reported as not found.
+func (rh RequestHandler) FromRevision(ctx context.Context, title string, at time.Time) (p WikiPage, err error) {
+	revID, p, err := rh.revisionAt(ctx, title, at)
+	if err != nil {
+		return WikiPage{}, err
+	}
+
+	query := rh.apiQuery(url.Values{
+		"action":  {"parse"},
+		"oldid":   {strconv.FormatUint(revID, 10)},
+		"prop":    {"text"},
+		"section": {"0"},
+	})
+	var data struct {
+		Parse struct {
+			Text string
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return WikiPage{}, err
+	}
+	if err = data.asError(title); err != nil {
+		return WikiPage{}, err
+	}
+
+	p.Abstract = plainParagraphs(data.Parse.Text)
+	return rh.finish(derive(p)), nil
+}
+
+// revisionAt returns the ID of the revision of the article with the specified title active at the specified time, with the page it belongs to.
+func (rh RequestHandler) revisionAt(ctx context.Context, title string, at time.Time) (revID uint64, p WikiPage, err error) {
+	query := rh.apiQuery(url.Values{
+		"action":    {"query"},
+		"prop":      {"revisions"},
+		"rvprop":    {"ids|timestamp"},
+		"rvlimit":   {"1"},
+		"rvdir":     {"older"},
+		"rvstart":   {at.UTC().Format(time.RFC3339)},
+		"redirects": {""},
+		"titles":    {title},
+	})
+
+	var data struct {
+		Query struct {
+			Normalized []struct{ From, To string }
+			Pages      []struct {
+				mayMissingPage
+				Revisions []struct {
+					RevID uint64
+				}
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing || len(data.Query.Pages[0].Revisions) == 0 {
+		return 0, WikiPage{}, errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	page := data.Query.Pages[0]
+	p = rh.localize(page.page())
+	p.RequestedTitle, p.NormalizedTitle = title, title
+	for _, n := range data.Query.Normalized {
+		p.NormalizedTitle = n.To
+	}
+	return page.Revisions[0].RevID, p, nil
+}
+
+var tagRule = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// plainParagraphs returns the plain text of the paragraphs of the rendered HTML, cut as the fall back API does to extractChars characters.
+func plainParagraphs(HTML string) string {
+	var paragraphs []string
+	for _, match := range paragraphElementRule.FindAllStringSubmatch(HTML, -1) {
+		text := html.UnescapeString(tagRule.ReplaceAllString(match[1], ""))
+		if text = strings.TrimSpace(citationRule.ReplaceAllString(text, "")); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+
+	text := []rune(strings.Join(paragraphs, "\n"))
+	if len(text) > extractChars {
+		return string(text[:extractChars-3]) + "..."
+	}
+	return string(text)
+}
@@ -0,0 +1,62 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromRevision(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("action") == "query" && q.Get("rvstart") != "2010-01-01T00:00:00Z":
+			t.Error("Unexpected rvstart", q.Get("rvstart"))
+		case q.Get("action") == "query" && q.Get("titles") == "anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"normalized":[{"fromencoded":false,"from":"anarchism","to":"Anarchism"}],"pages":[{"pageid":12,"ns":0,"title":"Anarchism","revisions":[{"revid":334950000,"parentid":334949000,"timestamp":"2009-12-31T20:00:00Z"}]}]}}`)
+		case q.Get("action") == "query":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":99,"ns":0,"title":"Newer"}]}}`)
+		case q.Get("oldid") == "334950000":
+			fmt.Fprint(w, `{"parse":{"title":"Anarchism","pageid":12,"revid":334950000,"text":"<div class=\"mw-parser-output\"><table class=\"infobox\"><tr><td>Infobox</td></tr></table><p><b>Anarchism</b> is a political philosophy &amp; movement.<sup class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup>\n</p><p>It holds the state to be undesirable.</p></div>"}}`)
+		default:
+			t.Error("Unexpected query", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	at := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p, err := rh.FromRevision(context.Background(), "anarchism", at)
+	expected := WikiPage{ID: 12, Title: "Anarchism", Abstract: "Anarchism is a political philosophy & movement.\nIt holds the state to be undesirable.", AbstractScope: "intro", Lang: "mytest", Dir: "ltr", DisplayTitle: "Anarchism", RequestedTitle: "anarchism", NormalizedTitle: "Anarchism"}
+	switch {
+	case err != nil:
+		t.Error("FromRevision returns", err)
+	case !reflect.DeepEqual(p, expected):
+		t.Error("FromRevision returns", p, "expected", expected)
+	}
+
+	if _, err := rh.FromRevision(context.Background(), "Newer", at); err == nil {
+		t.Error("FromRevision should fail on articles created later")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("FromRevision returns", err, "expected not found")
+	}
+}
+
+func TestPlainParagraphs(t *testing.T) {
+	for HTML, expected := range map[string]string{
+		"<p>Rome is the capital.[1]</p>":                                                      "Rome is the capital.",
+		"<p>Rome is the capital.[note 1][clarification needed]</p>":                           "Rome is the capital.",
+		"<p>Rome is the capital.[Citation needed]</p><p>[a]</p>":                              "Rome is the capital.",
+		`<P class="lead">Rome is the capital.</P><p id="x">It has 2.8 million residents.</p>`: "Rome is the capital.\nIt has 2.8 million residents.",
+	} {
+		if text := plainParagraphs(HTML); text != expected {
+			t.Errorf("plainParagraphs(%q) returns %q, expected %q", HTML, text, expected)
+		}
+	}
+}
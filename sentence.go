@@ -0,0 +1,75 @@
+package wikipage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// abbreviations are the common abbreviations whose trailing period doesn't end a sentence, in lower case.
+var abbreviations = func() map[string]struct{} {
+	abbreviations := map[string]struct{}{}
+	for _, a := range strings.Fields("mr mrs ms dr prof st jr sr mt ft gen col lt sgt rev gov sen rep inc ltd co corp no nos vol vols pp ed eds fig approx ca c cf al etc vs viz jan feb mar apr jun jul aug sep sept oct nov dec") {
+		abbreviations[a] = struct{}{}
+	}
+	return abbreviations
+}()
+
+// wholeSentences trims text back to its last complete sentence, ended by ".", "!" or "?" and possibly closing quotes or brackets.
+// Periods of abbreviations ("Dr.", "e.g.", "U.S.") and decimals ("3.14") don't end sentences. Text without complete sentences is returned as is.
+func wholeSentences(text string) string {
+	text = strings.TrimSpace(text)
+	trimmed := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "..."), "…"))
+	runes := []rune(trimmed)
+
+	end := -1
+	for i := 0; i < len(runes); i++ {
+		if r := runes[i]; r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && strings.ContainsRune(`"')]»”’`, runes[j]) {
+			j++
+		}
+		if j < len(runes) && !unicode.IsSpace(runes[j]) { //e.g. decimals and dotted abbreviations
+			continue
+		}
+		if runes[i] == '.' && !endsSentence(runes[:i], runes[j:]) {
+			continue
+		}
+		end = j
+	}
+
+	if end < 0 {
+		return text
+	}
+	return string(runes[:end])
+}
+
+// endsSentence checks if a period between before and after ends a sentence, rather than an abbreviation.
+func endsSentence(before, after []rune) bool {
+	//The word ended by the period
+	start := len(before)
+	for start > 0 && !unicode.IsSpace(before[start-1]) && !strings.ContainsRune(`"'([«“‘`, before[start-1]) {
+		start--
+	}
+	word := string(before[start:])
+
+	switch {
+	case word == "":
+		return true
+	case strings.Contains(word, ".") && strings.IndexFunc(word, func(r rune) bool { return r != '.' && !unicode.IsLetter(r) }) < 0: //Dotted abbreviations, as "e.g" or "U.S"
+		return false
+	case len([]rune(word)) == 1 && unicode.IsUpper([]rune(word)[0]): //Initials
+		return false
+	}
+	if _, ok := abbreviations[strings.ToLower(word)]; ok {
+		return false
+	}
+
+	//A sentence can't continue in lower case
+	next := strings.TrimLeftFunc(string(after), unicode.IsSpace)
+	for _, r := range next {
+		return !unicode.IsLower(r)
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWholeSentences(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Anarchism is a political philosophy. It is sceptical of authority and":                    "Anarchism is a political philosophy.",
+		"Pi is approximately 3.14. It is irrational and its decimal repr...":                       "Pi is approximately 3.14.",
+		"The U.S. is a country. Its capital is Washington, D.C. The country is large and…":         "The U.S. is a country.",
+		"Dr. Smith arrived (at noon). Then what? He left! Others stayed, e.g. the staff, until Mr": "Dr. Smith arrived (at noon). Then what? He left!",
+		"J. R. R. Tolkien wrote \"The Hobbit.\" It was published in 1937 by":                       "J. R. R. Tolkien wrote \"The Hobbit.\"",
+		"The population was approx. five million in the":                                           "The population was approx. five million in the",
+		"A complete sentence.": "A complete sentence.",
+	} {
+		if result := wholeSentences(text); result != expected {
+			t.Errorf("For %q expected %q, got %q", text, expected, result)
+		}
+	}
+}
+
+func TestSentenceTruncation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy. It is sceptical of authority and..."}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithSentenceTruncation())
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	switch p, err := rh.From(context.Background(), "Anarchism"); {
+	case err != nil:
+		t.Error("From returns", err)
+	case p.Abstract != "Anarchism is a political philosophy." || !p.Truncated:
+		t.Error("From returns", p)
+	}
+}
+
+func TestSentenceTruncationBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy. It is sceptical of authority and..."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithBaseURL(server.URL), WithSentenceTruncation())
+	title2Page, title2Error := rh.FromTitles(context.Background(), []string{"Anarchism"})
+	if p := title2Page["Anarchism"]; p.Abstract != "Anarchism is a political philosophy." || !p.Truncated {
+		t.Error("FromTitles returns", p, title2Error)
+	}
+	ID2Page, ID2Error := rh.FromIDs(context.Background(), []PageID{12})
+	if p := ID2Page[12]; p.Abstract != "Anarchism is a political philosophy." || !p.Truncated {
+		t.Error("FromIDs returns", p, ID2Error)
+	}
+}
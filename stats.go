@@ -0,0 +1,40 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+)
+
+// SiteStats are the statistics of a wiki.
+type SiteStats struct {
+	Articles    uint64 //Content pages, the articles in the main namespace
+	Pages       uint64 //All pages, in any namespace, redirects included
+	Edits       uint64
+	Images      uint64 //Files uploaded to the wiki itself
+	Users       uint64 //Registered users
+	ActiveUsers uint64 `json:"activeusers"` //Users who performed an action in the last 30 days
+	Admins      uint64
+}
+
+// SiteStats returns the statistics of the wiki, with a single lightweight query: e.g. the total articles as the denominator of the coverage of a crawl.
+func (rh RequestHandler) SiteStats(ctx context.Context) (stats *SiteStats, err error) {
+	query := rh.apiQuery(url.Values{
+		"action": {"query"},
+		"meta":   {"siteinfo"},
+		"siprop": {"statistics"},
+	})
+
+	var data struct {
+		Query struct {
+			Statistics SiteStats
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError("siteinfo"); err != nil {
+		return
+	}
+	return &data.Query.Statistics, nil
+}
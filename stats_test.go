@@ -0,0 +1,30 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSiteStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("meta") != "siteinfo" || q.Get("siprop") != "statistics" {
+			t.Error("Unexpected query", q)
+		}
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"statistics":{"pages":61519367,"articles":6912345,"edits":1245678901,"images":912345,"users":48765432,"activeusers":121234,"admins":850,"jobs":0,"cirrussearch-article-words":4712345678}}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+
+	stats, err := rh.SiteStats(context.Background())
+	switch expected := (SiteStats{6912345, 61519367, 1245678901, 912345, 48765432, 121234, 850}); {
+	case err != nil:
+		t.Error("SiteStats returns ", err)
+	case stats == nil || *stats != expected:
+		t.Error("SiteStats returns", stats, "expected", expected)
+	}
+}
@@ -0,0 +1,64 @@
+package wikipage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TitleMismatch is the error returned by From, when WithStrictTitleMatch is in use, for replies about a page whose title isn't explained
+// by the normalizations and redirects of the requested one. From doesn't retry on it.
+type TitleMismatch struct {
+	Title         string //Title requested
+	ResolvedTitle string //Title of the page in the reply
+}
+
+func (err TitleMismatch) Error() string {
+	return fmt.Sprintf("%v resolved to the unrelated page %v", err.Title, err.ResolvedTitle)
+}
+
+// titleExplained checks if title resolves to resolvedTitle through hops, the normalizations and redirects reported by the API from the title they apply to.
+// caseSensitive tells if the wiki keeps the case of the first letter of titles.
+func titleExplained(title, resolvedTitle string, hops map[string]string, caseSensitive bool) bool {
+	for i := 0; i <= maxRedirectHops; i++ {
+		if sameTitle(title, resolvedTitle, caseSensitive) {
+			return true
+		}
+		next, ok := hops[title]
+		if !ok {
+			next, ok = hops[spaceRule.Replace(title)]
+		}
+		if !ok {
+			return false
+		}
+		title = next
+	}
+	return false
+}
+
+var spaceRule = strings.NewReplacer("_", " ")
+
+// sameTitle checks if the titles are the same, up to underscores and, unless caseSensitive, the capitalization of the first letter, as by the wikis normalization.
+func sameTitle(title, otherTitle string, caseSensitive bool) bool {
+	title, otherTitle = spaceRule.Replace(title), spaceRule.Replace(otherTitle)
+	if title == otherTitle || caseSensitive {
+		return title == otherTitle
+	}
+	r, size := utf8.DecodeRuneInString(title)
+	return string(unicode.ToUpper(r))+title[size:] == otherTitle
+}
+
+// restTitle returns the title of the page of the REST API URL rawURL, e.g. the target of a redirect followed.
+func restTitle(rawURL string) (title string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	i := strings.LastIndex(u.Path, "/page/summary/")
+	if i < 0 {
+		return "", false
+	}
+	return spaceRule.Replace(u.Path[i+len("/page/summary/"):]), true
+}
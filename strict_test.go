@@ -0,0 +1,81 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestStrictTitleMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch title := r.URL.Query().Get("titles"); {
+		case r.URL.Path == "/api/rest_v1/page/summary/Anarchy":
+			http.Redirect(w, r, "/api/rest_v1/page/summary/Anarchism", http.StatusFound)
+		case r.URL.Path == "/api/rest_v1/page/summary/Anarchism", r.URL.Path == "/api/rest_v1/page/summary/Glitch":
+			fmt.Fprint(w, `{"pageid":12,"title":"Anarchism","extract":"Anarchism is a political philosophy."}`)
+		case title == "anarchy":
+			fmt.Fprint(w, `{"query":{"normalized":[{"from":"anarchy","to":"Anarchy"}],"redirects":[{"from":"Anarchy","to":"Anarchism"}],`+
+				`"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+		default:
+			fmt.Fprint(w, `{"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithStrictTitleMatch())
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+
+	for _, test := range []struct {
+		title    string
+		options  []CallOption
+		mismatch bool
+	}{
+		{"Anarchism", nil, false},
+		{"Anarchy", nil, false},
+		{"Glitch", nil, true},
+		{"anarchy", []CallOption{ForceFallback()}, false},
+		{"Glitch", []CallOption{ForceFallback()}, true},
+	} {
+		p, err := rh.From(context.Background(), test.title, test.options...)
+		mismatch, isMismatch := errors.Cause(err).(TitleMismatch)
+		switch {
+		case test.mismatch && !isMismatch:
+			t.Errorf("%v %v should fail with TitleMismatch, instead it returns %v", test.title, test.options, err)
+		case test.mismatch && mismatch != TitleMismatch{test.title, "Anarchism"}:
+			t.Errorf("%v %v returns the wrong TitleMismatch %#v", test.title, test.options, mismatch)
+		case !test.mismatch && (err != nil || p.Title != "Anarchism"):
+			t.Errorf("%v %v should resolve to Anarchism, instead it returns %v, %v", test.title, test.options, p.Title, err)
+		}
+	}
+
+	rh = New("mytest")
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if p, err := rh.From(context.Background(), "Glitch"); err != nil || p.Title != "Anarchism" {
+		t.Error("Without WithStrictTitleMatch titles shouldn't be checked, instead From returns", p.Title, err)
+	}
+}
+
+func TestTitleExplained(t *testing.T) {
+	hops := map[string]string{"anarchy": "Anarchy", "Anarchy": "Anarchism"}
+	for _, test := range []struct {
+		title, resolvedTitle string
+		explained            bool
+	}{
+		{"Anarchism", "Anarchism", true},
+		{"anarchism", "Anarchism", true},
+		{"Political_philosophy", "Political philosophy", true},
+		{"anarchy", "Anarchism", true},
+		{"Glitch", "Anarchism", false},
+		{"Anarchism", "anarchism", false},
+	} {
+		if explained := titleExplained(test.title, test.resolvedTitle, hops, false); explained != test.explained {
+			t.Errorf("titleExplained(%v, %v) returns %v, expected %v", test.title, test.resolvedTitle, explained, test.explained)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// stubLength is the size in bytes of the article source below which an article is considered a stub.
+const stubLength = 1500
+
+// IsStub checks if the article with the specified title, following redirects, is a stub. Since stubs aren't formally defined, it uses a heuristic:
+// an article is a stub if it belongs to a category whose name ends with "stub" or "stubs" (as the ones populated by stub templates, e.g. "Category:Physics stubs"),
+// or if its source is shorter than 1500 bytes. Category names are matched case insensitively, but they are localized, so on non English wikis only the length counts.
+func (rh RequestHandler) IsStub(ctx context.Context, title string) (stub bool, err error) {
+	params := url.Values{
+		"action":    {"query"},
+		"prop":      {"categories|info"},
+		"cllimit":   {"max"},
+		"redirects": {""},
+		"titles":    {title},
+	}
+
+	found, length := false, uint32(0)
+	err = rh.queryAll(ctx, params, func(body []byte) error {
+		var data struct {
+			Query struct {
+				Pages []struct {
+					mayMissingPage
+					Categories []struct{ Title string }
+				}
+			}
+		}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return err
+		}
+
+		//Categories may be spread across continuations
+		for _, p := range data.Query.Pages {
+			if p.Missing {
+				continue
+			}
+			found = true
+			if p.Length != 0 {
+				length = p.Length
+			}
+			for _, c := range p.Categories {
+				if c := strings.ToLower(c.Title); strings.HasSuffix(c, "stub") || strings.HasSuffix(c, "stubs") {
+					stub = true
+				}
+			}
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return false, err
+	case !found:
+		return false, errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	return stub || length < stubLength, nil
+}
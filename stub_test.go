@@ -0,0 +1,40 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsStub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch title, clcontinue := r.URL.Query().Get("titles"), r.URL.Query().Get("clcontinue"); {
+		case title == "Rome":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","length":213475,"categories":[{"ns":14,"title":"Category:Capitals in Europe"}]}]}}`)
+		case title == "Bosco Chiesanuova" && clcontinue == "":
+			fmt.Fprint(w, `{"continue":{"clcontinue":"1|Veneto","continue":"||info"},"query":{"pages":[{"pageid":7,"ns":0,"title":"Bosco Chiesanuova","length":4210,"categories":[{"ns":14,"title":"Category:Cities and towns in Veneto"}]}]}}`)
+		case title == "Bosco Chiesanuova":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":7,"ns":0,"title":"Bosco Chiesanuova","categories":[{"ns":14,"title":"Category:Veneto geography stubs"}]}]}}`)
+		case title == "Brief":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":8,"ns":0,"title":"Brief","length":900}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Missing","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	for title, expected := range map[string]bool{"Rome": false, "Bosco Chiesanuova": true, "Brief": true} {
+		if stub, err := rh.IsStub(context.Background(), title); err != nil || stub != expected {
+			t.Error("IsStub of", title, "returns", stub, err, "expected", expected)
+		}
+	}
+	if _, err := rh.IsStub(context.Background(), "Missing"); err == nil {
+		t.Error("IsStub should fail on a missing page")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("IsStub returns", err, "expected not found")
+	}
+}
@@ -0,0 +1,66 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Templates returns the titles of the templates transcluded, directly or not, in the article with the specified title, following redirects,
+// as "Template:Infobox country": TemplateName strips their namespace prefix.
+func (rh RequestHandler) Templates(ctx context.Context, title string) (templates []string, err error) {
+	params := url.Values{
+		"action":      {"query"},
+		"prop":        {"templates"},
+		"tllimit":     {"max"},
+		"tlnamespace": {"10"},
+		"redirects":   {""},
+		"titles":      {title},
+	}
+
+	found := false
+	err = rh.queryAll(ctx, params, func(body []byte) error {
+		var data struct {
+			Query struct {
+				Pages []struct {
+					mayMissingPage
+					Templates []struct{ Title string }
+				}
+			}
+		}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return err
+		}
+
+		//Templates are spread across continuations
+		for _, p := range data.Query.Pages {
+			if p.Missing {
+				continue
+			}
+			found = true
+			for _, t := range p.Templates {
+				templates = append(templates, t.Title)
+			}
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case !found:
+		return nil, errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	return templates, nil
+}
+
+// TemplateName strips the namespace prefix from a template title as returned by Templates, e.g. "Template:Infobox country" becomes "Infobox country".
+// The prefix is localized, e.g. "Vorlage:" on the German Wikipedia, so everything up to the first colon is stripped.
+func TemplateName(title string) string {
+	if i := strings.Index(title, ":"); i >= 0 {
+		return title[i+1:]
+	}
+	return title
+}
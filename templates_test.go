@@ -0,0 +1,49 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch title, tlcontinue := r.URL.Query().Get("titles"), r.URL.Query().Get("tlcontinue"); {
+		case r.URL.Query().Get("tlnamespace") != "10":
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+		case title == "Rome" && tlcontinue == "":
+			fmt.Fprint(w, `{"continue":{"tlcontinue":"45|10|Citation","continue":"||"},"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","templates":[{"ns":10,"title":"Template:Infobox settlement"}]}]}}`)
+		case title == "Rome":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":45,"ns":0,"title":"Rome","templates":[{"ns":10,"title":"Template:Citation"}]}]}}`)
+		case title == "Plain":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":8,"ns":0,"title":"Plain"}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Missing","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	for title, expected := range map[string][]string{"Rome": {"Template:Infobox settlement", "Template:Citation"}, "Plain": nil} {
+		if templates, err := rh.Templates(context.Background(), title); err != nil || !reflect.DeepEqual(templates, expected) {
+			t.Error("Templates of", title, "returns", templates, err, "expected", expected)
+		}
+	}
+	if _, err := rh.Templates(context.Background(), "Missing"); err == nil {
+		t.Error("Templates should fail on a missing page")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("Templates returns", err, "expected not found")
+	}
+}
+
+func TestTemplateName(t *testing.T) {
+	for title, expected := range map[string]string{"Template:Infobox country": "Infobox country", "Vorlage:Infobox Staat": "Infobox Staat", "Citation": "Citation"} {
+		if name := TemplateName(title); name != expected {
+			t.Error("TemplateName of", title, "returns", name, "expected", expected)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FromURL returns the WikiPage of the article with the specified URL, as https://en.wikipedia.org/wiki/Anarchism or
+// https://en.m.wikipedia.org/w/index.php?title=Anarchism, using a RequestHandler with default options for the wiki of the URL,
+// created once for every wiki. Like From, it's advised to setup a timeout with the context.
+func FromURL(ctx context.Context, rawurl string) (WikiPage, error) {
+	lang, project, title, err := parseWikiURL(rawurl)
+	if err != nil {
+		return WikiPage{}, err
+	}
+	return urlHandler(lang, project).From(ctx, title)
+}
+
+var (
+	urlHandlersMutex sync.Mutex
+	urlHandlers      = map[string]RequestHandler{}
+)
+
+// urlHandler returns the RequestHandler used by FromURL for the wiki of the specified project in the specified language.
+func urlHandler(lang, project string) RequestHandler {
+	urlHandlersMutex.Lock()
+	defer urlHandlersMutex.Unlock()
+
+	key := lang + "." + project
+	rh, ok := urlHandlers[key]
+	if !ok {
+		rh = New(lang, WithProject(project))
+		urlHandlers[key] = rh
+	}
+	return rh
+}
+
+// parseWikiURL splits the URL of an article into the language and the project of the wiki and the title of the article.
+func parseWikiURL(rawurl string) (lang, project, title string, err error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", "", "", errors.Wrapf(err, "invalid article URL %v", rawurl)
+	}
+
+	//Host is <lang>.<project>.org or, for mobile, <lang>.m.<project>.org
+	labels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	if len(labels) == 4 && labels[1] == "m" {
+		labels = append(labels[:1], labels[2:]...)
+	}
+	if len(labels) != 3 || labels[2] != "org" {
+		return "", "", "", errors.Errorf("invalid article URL %v: unexpected host", rawurl)
+	}
+	lang, project = labels[0], labels[1]
+
+	switch {
+	case strings.HasPrefix(u.Path, "/wiki/"):
+		title = strings.TrimPrefix(u.Path, "/wiki/")
+	case u.Path == "/w/index.php":
+		title = u.Query().Get("title")
+	}
+	if title = strings.TrimSpace(strings.Replace(title, "_", " ", -1)); title == "" {
+		return "", "", "", errors.Errorf("invalid article URL %v: no title", rawurl)
+	}
+	return
+}
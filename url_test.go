@@ -0,0 +1,32 @@
+package wikipage
+
+import (
+	"testing"
+)
+
+func TestParseWikiURL(t *testing.T) {
+	for rawurl, expected := range map[string][3]string{
+		"https://en.wikipedia.org/wiki/Anarchism":                       {"en", "wikipedia", "Anarchism"},
+		"https://en.wikipedia.org/wiki/New_York_City#History":           {"en", "wikipedia", "New York City"},
+		"https://it.m.wikipedia.org/wiki/Citt%C3%A0_del_Vaticano":       {"it", "wikipedia", "Città del Vaticano"},
+		"https://en.wikipedia.org/w/index.php?title=AC%2FDC&oldid=1234": {"en", "wikipedia", "AC/DC"},
+		"https://de.wiktionary.org/wiki/Haus":                           {"de", "wiktionary", "Haus"},
+	} {
+		switch lang, project, title, err := parseWikiURL(rawurl); {
+		case err != nil:
+			t.Error("For", rawurl, "got", err)
+		case [3]string{lang, project, title} != expected:
+			t.Error("For", rawurl, "expected", expected, "got", lang, project, title)
+		}
+	}
+
+	for _, rawurl := range []string{"https://example.com/wiki/Anarchism", "https://en.wikipedia.org/", "https://en.wikipedia.org/w/index.php", "%"} {
+		if _, _, _, err := parseWikiURL(rawurl); err == nil {
+			t.Error("For", rawurl, "expected an error")
+		}
+	}
+
+	if urlHandler("en", "wikipedia").negativeCache != urlHandler("en", "wikipedia").negativeCache {
+		t.Error("Handlers should be reused for the same wiki")
+	}
+}
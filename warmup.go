@@ -0,0 +1,46 @@
+package wikipage
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Warmup issues n minimal requests to the wiki in parallel, respecting the rate limiter, so that as many keep-alive connections are opened,
+// TLS handshakes included, and the first calls of the handler aren't slowed down by them: e.g. at service start. Unlike HealthCheck, it doesn't
+// check the replies beyond their status. A non positive n is a no-op; otherwise it returns the first error, if any.
+func (rh RequestHandler) Warmup(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	query := rh.apiQuery(url.Values{
+		"action": {"query"},
+		"meta":   {"siteinfo"},
+	})
+
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, status, err := rh.fetch(ctx, query)
+			if err == nil && status != http.StatusOK {
+				err = errors.Errorf("unexpected status %v", status)
+			}
+			errs <- errors.Wrapf(err, "error with the following query: %v", query)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
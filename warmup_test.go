@@ -0,0 +1,34 @@
+package wikipage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get("meta") != "siteinfo" {
+			t.Error("Unexpected query", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	if err := rh.Warmup(context.Background(), -1); err != nil || requests != 0 {
+		t.Error("Warmup with no requests should be a no-op, instead it returns", err, "after", requests, "requests")
+	}
+	if err := rh.Warmup(context.Background(), 4); err != nil || requests != 4 {
+		t.Error("Warmup returns", err, "after", requests, "requests")
+	}
+
+	server.Close()
+	if err := rh.Warmup(context.Background(), 2); err == nil {
+		t.Error("Warmup should fail for unreachable wikis")
+	}
+}
@@ -0,0 +1,282 @@
+package wikipage
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FromWikidata returns the WikiPage of the article linked to the specified Wikidata item (e.g. "Q42") on the handler wiki, looking up its title on Wikidata
+// and then retrieving it as From does. Items without an article on the handler wiki are reported as not found.
+func (rh RequestHandler) FromWikidata(ctx context.Context, QID string, options ...CallOption) (WikiPage, error) {
+	rh, _ = rh.with(options...)
+	QID2Title, err := rh.sitelinks(ctx, []string{QID})
+	if err != nil {
+		return WikiPage{}, err
+	}
+
+	title := QID2Title[QID]
+	if title == "" {
+		return WikiPage{}, errors.WithStack(pageNotFound{title: QID, endpoint: "wikidata"})
+	}
+	return rh.From(ctx, title, options...)
+}
+
+// FromWikidataBatch is the bulk counterpart of FromWikidata: it looks up the titles of the specified Wikidata items with a single query
+// for every batch of 50 items, and then retrieves the articles as FromTitles does. Items without an article on the handler wiki,
+// and the ones that couldn't be retrieved, are reported in the error map.
+func (rh RequestHandler) FromWikidataBatch(ctx context.Context, QIDs []string, options ...CallOption) (QID2Page map[string]WikiPage, QID2Error map[string]error) {
+	QID2Page, QID2Error = make(map[string]WikiPage, len(QIDs)), map[string]error{}
+	rh, _ = rh.with(options...)
+
+	//Duplicate items are queried once
+	var unique []string
+	seen := make(map[string]bool, len(QIDs))
+	for _, QID := range QIDs {
+		if !seen[QID] {
+			seen[QID] = true
+			unique = append(unique, QID)
+		}
+	}
+
+	title2QIDs := map[string][]string{}
+	var titles []string
+	for len(unique) > 0 {
+		batch := unique
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		unique = unique[len(batch):]
+
+		QID2Title, err := rh.sitelinks(ctx, batch)
+		for _, QID := range batch {
+			switch title := QID2Title[QID]; {
+			case err != nil:
+				QID2Error[QID] = err
+			case title == "":
+				QID2Error[QID] = errors.WithStack(pageNotFound{title: QID, endpoint: "wikidata"})
+			default:
+				if len(title2QIDs[title]) == 0 {
+					titles = append(titles, title)
+				}
+				title2QIDs[title] = append(title2QIDs[title], QID)
+			}
+		}
+	}
+
+	title2Page, title2Error := rh.FromTitles(ctx, titles, options...)
+	for title, QIDs := range title2QIDs {
+		for _, QID := range QIDs {
+			if err, failed := title2Error[title]; failed {
+				QID2Error[QID] = err
+			} else {
+				QID2Page[QID] = title2Page[title]
+			}
+		}
+	}
+	return
+}
+
+// sitelinks looks up on Wikidata the titles of the articles linked to the specified items on the handler wiki, 50 at most:
+// items without an article are left out.
+func (rh RequestHandler) sitelinks(ctx context.Context, QIDs []string) (QID2Title map[string]string, err error) {
+	site := siteOf(rh.lang, rh.project)
+	var data struct {
+		Entities map[string]struct {
+			Sitelinks map[string]struct {
+				Title string
+			}
+		}
+	}
+	err = rh.getEntities(ctx, url.Values{
+		"ids":        {strings.Join(QIDs, "|")},
+		"props":      {"sitelinks"},
+		"sitefilter": {site},
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	QID2Title = make(map[string]string, len(data.Entities))
+	for QID, entity := range data.Entities {
+		if title := entity.Sitelinks[site].Title; title != "" {
+			QID2Title[QID] = title
+		}
+	}
+	return QID2Title, nil
+}
+
+// Descriptions returns the short descriptions, by language, of the article with the specified title: they are looked up on the Wikidata item of the article,
+// resolved as From does, so that they're available in the specified languages (e.g. "en", "it" and "fr") or all of them if none is specified.
+// Languages without a description are left out; articles without an item are reported as not found.
+func (rh RequestHandler) Descriptions(ctx context.Context, title string, langs ...string) (lang2Description map[string]string, err error) {
+	QID, err := rh.wikibaseItem(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{
+		"ids":   {QID},
+		"props": {"descriptions"},
+	}
+	if len(langs) > 0 {
+		params.Set("languages", strings.Join(langs, "|"))
+	}
+	var data struct {
+		Entities map[string]struct {
+			Descriptions map[string]struct {
+				Value string
+			}
+		}
+	}
+	if err = rh.getEntities(ctx, params, &data); err != nil {
+		return nil, err
+	}
+
+	lang2Description = map[string]string{}
+	for lang, description := range data.Entities[QID].Descriptions {
+		lang2Description[lang] = description.Value
+	}
+	return lang2Description, nil
+}
+
+// EditionCount returns the number of language editions of the handler project with the article with the specified title, the handler one included,
+// as counted by the sitelinks of its Wikidata item, resolved as From does: it's much cheaper than retrieving all the language links of the article.
+// Articles without an item are reported as not found.
+func (rh RequestHandler) EditionCount(ctx context.Context, title string) (count int, err error) {
+	QID, err := rh.wikibaseItem(ctx, title)
+	if err != nil {
+		return 0, err
+	}
+
+	var data struct {
+		Entities map[string]struct {
+			Sitelinks map[string]json.RawMessage
+		}
+	}
+	if err = rh.getEntities(ctx, url.Values{"ids": {QID}, "props": {"sitelinks"}}, &data); err != nil {
+		return 0, err
+	}
+
+	suffix := siteOf("", rh.project)
+	for site := range data.Entities[QID].Sitelinks {
+		if _, multilingual := multilingualSites[site]; strings.HasSuffix(site, suffix) && !multilingual {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// multilingualSites are the Wikimedia sites sharing the site ID suffix of Wikipedia, but not a language edition.
+var multilingualSites = map[string]struct{}{
+	"commonswiki": {}, "specieswiki": {}, "metawiki": {}, "wikidatawiki": {}, "mediawikiwiki": {}, "sourceswiki": {},
+	"incubatorwiki": {}, "wikimaniawiki": {}, "outreachwiki": {}, "foundationwiki": {}, "wikifunctionswiki": {}, "testwiki": {},
+}
+
+// wikibaseItem returns the Wikidata item of the article with the specified title, resolved as From does.
+func (rh RequestHandler) wikibaseItem(ctx context.Context, title string) (QID string, err error) {
+	p, err := rh.From(ctx, title, Include(ExtraWikibase))
+	switch {
+	case err != nil:
+		return "", err
+	case p.WikibaseItem == "":
+		return "", errors.WithStack(pageNotFound{title: title, endpoint: "wikidata"})
+	default:
+		return p.WikibaseItem, nil
+	}
+}
+
+// getEntities issues the wbgetentities query to Wikidata with the specified parameters and unmarshals its JSON body into v.
+func (rh RequestHandler) getEntities(ctx context.Context, params url.Values, v interface{}) error {
+	params.Set("action", "wbgetentities")
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	query := rh.wikidataURL + "/w/api.php?" + params.Encode()
+
+	var data struct {
+		apiErrors
+	}
+	body, _, err := rh.fetch(ctx, query)
+	if err == nil {
+		err = decode(body, &data)
+	}
+	if apiErr := data.first(); err == nil && apiErr != nil {
+		err = errors.Errorf("%v (%v)", apiErr.Info, apiErr.Code)
+	}
+	if err == nil {
+		err = decode(body, v)
+	}
+	return errors.Wrapf(err, "error with the following query: %v", query)
+}
+
+// Editions returns the articles linked to the specified Wikidata item (e.g. "Q42") in the specified languages of the handler project, by language:
+// it looks up their titles on Wikidata with a single query, and then retrieves them concurrently as From does. Languages without an article are left out.
+func (rh RequestHandler) Editions(ctx context.Context, QID string, langs ...string) (lang2Page map[string]WikiPage, err error) {
+	lang2Page = map[string]WikiPage{}
+	if len(langs) == 0 {
+		return
+	}
+
+	site2Lang := make(map[string]string, len(langs))
+	for _, lang := range langs {
+		site2Lang[siteOf(lang, rh.project)] = lang
+	}
+	sites := make([]string, 0, len(site2Lang))
+	for site := range site2Lang {
+		sites = append(sites, site)
+	}
+	var data struct {
+		Entities map[string]struct {
+			Sitelinks map[string]struct {
+				Title string
+			}
+		}
+	}
+	err = rh.getEntities(ctx, url.Values{
+		"ids":        {QID},
+		"props":      {"sitelinks"},
+		"sitefilter": {strings.Join(sites, "|")},
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for site, sitelink := range data.Entities[QID].Sitelinks {
+		lang, ok := site2Lang[site]
+		if !ok || sitelink.Title == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(lang, title string) {
+			defer wg.Done()
+			p, pErr := rh.From(ctx, title, WithLang(lang))
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if _, notFound := NotFound(pErr); notFound {
+				return
+			}
+			if pErr != nil && err == nil {
+				err = pErr
+			}
+			lang2Page[lang] = p
+		}(lang, sitelink.Title)
+	}
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	return lang2Page, nil
+}
+
+// siteOf returns the Wikidata site ID of the wiki in the specified language of the specified project, e.g. "enwiki" or "zh_min_nanwiktionary".
+func siteOf(lang, project string) string {
+	return strings.Replace(lang, "-", "_", -1) + strings.TrimSuffix(project, "pedia")
+}
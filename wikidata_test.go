@@ -0,0 +1,161 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromWikidata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("action") != "wbgetentities":
+			fmt.Fprint(w, `{"type":"standard","title":"Douglas Adams","pageid":8091,"namespace":{"id":0},"extract":"Douglas Noel Adams was an English author."}`)
+		case q.Get("sitefilter") != "enwiki":
+			t.Error("Unexpected site", q.Get("sitefilter"))
+		case q.Get("ids") == "Q42":
+			fmt.Fprint(w, `{"entities":{"Q42":{"type":"item","id":"Q42","sitelinks":{"enwiki":{"site":"enwiki","title":"Douglas Adams","badges":[]}}}},"success":1}`)
+		default:
+			fmt.Fprint(w, `{"entities":{"Q4115189":{"type":"item","id":"Q4115189","sitelinks":{}}},"success":1}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("en")
+	rh.baseURL, rh.wikidataURL = server.URL, server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+
+	switch p, err := rh.FromWikidata(context.Background(), "Q42"); {
+	case err != nil:
+		t.Error("FromWikidata returns", err)
+	case p.Title != "Douglas Adams" || p.ID != 8091:
+		t.Error("FromWikidata returns", p)
+	}
+
+	_, err := rh.FromWikidata(context.Background(), "Q4115189")
+	if details, ok := NotFoundDetailsOf(err); !ok || details.Endpoint != "wikidata" {
+		t.Error("FromWikidata should report items without an article as not found, instead it returns", err)
+	}
+}
+
+func TestFromWikidataBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("action") == "query":
+			if titles := q.Get("titles"); titles != "Douglas Adams|Rome" {
+				t.Error("Unexpected titles", titles)
+			}
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":8091,"ns":0,"title":"Douglas Adams","extract":"Douglas Noel Adams was an English author."},{"pageid":25458,"ns":0,"title":"Rome","extract":"Rome is the capital city of Italy."}]}}`)
+		case q.Get("ids") != "Q42|Q220|Q4115189":
+			t.Error("Unexpected ids", q.Get("ids"))
+		default:
+			fmt.Fprint(w, `{"entities":{"Q42":{"type":"item","id":"Q42","sitelinks":{"enwiki":{"site":"enwiki","title":"Douglas Adams","badges":[]}}},"Q220":{"type":"item","id":"Q220","sitelinks":{"enwiki":{"site":"enwiki","title":"Rome","badges":[]}}},"Q4115189":{"type":"item","id":"Q4115189","sitelinks":{}}},"success":1}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("en")
+	rh.baseURL, rh.wikidataURL = server.URL, server.URL
+
+	QID2Page, QID2Error := rh.FromWikidataBatch(context.Background(), []string{"Q42", "Q220", "Q4115189", "Q42"})
+	for QID, title := range map[string]string{"Q42": "Douglas Adams", "Q220": "Rome"} {
+		if p, err := QID2Page[QID], QID2Error[QID]; err != nil || p.Title != title {
+			t.Error("For", QID, "FromWikidataBatch returns", p, err)
+		}
+	}
+	if details, ok := NotFoundDetailsOf(QID2Error["Q4115189"]); !ok || details.Endpoint != "wikidata" {
+		t.Error("FromWikidataBatch should report items without an article as not found, instead it returns", QID2Error["Q4115189"])
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("action") != "wbgetentities":
+			fmt.Fprint(w, `{"type":"standard","title":"Rome","pageid":25458,"namespace":{"id":0},"extract":"Rome is the capital city of Italy.","wikibase_item":"Q220"}`)
+		case q.Get("ids") != "Q220" || q.Get("props") != "descriptions" || q.Get("languages") != "en|it|xx":
+			t.Error("Unexpected query", q)
+		default:
+			fmt.Fprint(w, `{"entities":{"Q220":{"type":"item","id":"Q220","descriptions":{"en":{"language":"en","value":"capital city of Italy"},"it":{"language":"it","value":"capitale d'Italia"}}}},"success":1}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("en")
+	rh.baseURL, rh.wikidataURL = server.URL, server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+
+	lang2Description, err := rh.Descriptions(context.Background(), "Rome", "en", "it", "xx")
+	switch expected := map[string]string{"en": "capital city of Italy", "it": "capitale d'Italia"}; {
+	case err != nil:
+		t.Error("Descriptions returns", err)
+	case !reflect.DeepEqual(lang2Description, expected):
+		t.Error("Descriptions returns", lang2Description, "expected", expected)
+	}
+}
+
+// hostTransport serves every request through the server at addr, so that requests to any wiki can be checked.
+type hostTransport string
+
+func (addr hostTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = "http", string(addr)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestEditions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query(); {
+		case r.Host == "www.wikidata.org":
+			if sites := strings.Split(q.Get("sitefilter"), "|"); len(sites) != 3 {
+				t.Error("Unexpected sites", sites)
+			}
+			fmt.Fprint(w, `{"entities":{"Q220":{"type":"item","id":"Q220","sitelinks":{"enwiki":{"site":"enwiki","title":"Rome","badges":[]},"itwiki":{"site":"itwiki","title":"Roma","badges":[]}}}},"success":1}`)
+		case r.Host == "it.wikipedia.org" && strings.HasSuffix(r.URL.Path, "/Roma"):
+			fmt.Fprint(w, `{"type":"standard","title":"Roma","pageid":7,"namespace":{"id":0},"extract":"Roma è la capitale d'Italia."}`)
+		case r.Host == "en.wikipedia.org" && strings.HasSuffix(r.URL.Path, "/Rome"):
+			fmt.Fprint(w, `{"type":"standard","title":"Rome","pageid":25458,"namespace":{"id":0},"extract":"Rome is the capital city of Italy."}`)
+		default:
+			t.Error("Unexpected request", r.Host, r.URL)
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("en", WithHTTPClient(&http.Client{Transport: hostTransport(strings.TrimPrefix(server.URL, "http://"))}))
+	lang2Page, err := rh.Editions(context.Background(), "Q220", "en", "it", "fr")
+	switch {
+	case err != nil:
+		t.Error("Editions returns", err)
+	case len(lang2Page) != 2 || lang2Page["en"].Title != "Rome" || lang2Page["it"].Title != "Roma":
+		t.Error("Editions returns", lang2Page)
+	}
+}
+
+func TestEditionCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query(); {
+		case q.Get("action") != "wbgetentities":
+			fmt.Fprint(w, `{"type":"standard","title":"Rome","pageid":25458,"namespace":{"id":0},"extract":"Rome is the capital city of Italy.","wikibase_item":"Q220"}`)
+		case q.Get("ids") != "Q220" || q.Get("props") != "sitelinks" || q.Get("sitefilter") != "":
+			t.Error("Unexpected query", q)
+		default:
+			fmt.Fprint(w, `{"entities":{"Q220":{"type":"item","id":"Q220","sitelinks":{"enwiki":{"site":"enwiki","title":"Rome","badges":[]},"itwiki":{"site":"itwiki","title":"Roma","badges":[]},"zh_min_nanwiki":{"site":"zh_min_nanwiki","title":"Roma","badges":[]},"enwikiquote":{"site":"enwikiquote","title":"Rome","badges":[]},"enwikivoyage":{"site":"enwikivoyage","title":"Rome","badges":[]},"commonswiki":{"site":"commonswiki","title":"Category:Roma","badges":[]}}}},"success":1}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("en")
+	rh.baseURL, rh.wikidataURL = server.URL, server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if count, err := rh.EditionCount(context.Background(), "Rome"); err != nil || count != 3 {
+		t.Error("EditionCount returns", count, err)
+	}
+}
@@ -2,196 +2,1128 @@
 package wikipage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/time/rate"
 
 	"github.com/pkg/errors"
 )
 
+// PageID is the identifier of a Wikipedia page.
+type PageID uint32
+
+func (id PageID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
 // WikiPage represents an article of Wikipedia.
 type WikiPage struct {
-	ID       uint32 `json:"pageid"`
-	Title    string
-	Abstract string `json:"Extract"`
+	ID        PageID `json:"pageid"`
+	Title     string
+	Abstract  string `json:"Extract"`
+	Namespace int    `json:"ns"`
+	Truncated bool   //Abstract has been cut short and continues in the article
+	//Part of the article Abstract is drawn from: "summary" for the REST API, which summarizes the article, "intro" for the fall back API, which extracts its introduction.
+	AbstractScope string `json:"abstract_scope"`
+	//Abstract as HTML, with markup and links. Only the REST API provides it, in the same reply, otherwise it's empty.
+	AbstractHTML string `json:"extract_html"`
+
+	//Language code and direction, "ltr" or "rtl", of the text, for rendering. Only the REST API reports them, otherwise they're inferred from the language of the RequestHandler.
+	Lang string `json:"lang"`
+	Dir  string `json:"dir"`
+
+	//Title as displayed, possibly containing HTML markup. Only the REST API provides it, otherwise it's the plain Title.
+	DisplayTitle string
+
+	//Title as requested to From, which may differ from Title because of normalization and redirects.
+	RequestedTitle string
+	//Normalized form of RequestedTitle, before following redirects. Only the fall back API reports normalization, otherwise it's RequestedTitle.
+	NormalizedTitle string
+
+	//Extras, filled only when requested through Include and available.
+	Description       string            `json:"description"`
+	DescriptionSource string            `json:"description_source"` //"local" or "central" (Wikidata), only the REST API reports it
+	Thumbnail         Image             `json:"thumbnail"`
+	Coordinates       Coordinates       `json:"coordinates"`
+	WikibaseItem      string            `json:"wikibase_item"`
+	Length            uint32            `json:"length"` //Size in bytes of the article source
+	PageProps         map[string]string `json:"page_props"`
+}
+
+// New loads or creates a RequestHandler for the specified language, optionally customized through options.
+func New(lang string, options ...Option) (rh RequestHandler) {
+	client, limiter := sharedClientAndLimiter()
+	rh = RequestHandler{
+		lang:          lang,
+		project:       DefaultProject,
+		baseURL:       wikiURL(lang, DefaultProject),
+		wikidataURL:   "https://www.wikidata.org",
+		pageviewsURL:  "https://wikimedia.org/api/rest_v1",
+		client:        client,
+		limiter:       limiter,
+		userAgent:     envUserAgent,
+		clock:         realClock{},
+		negativeCache: newNegativeCache(DefaultNegativeCacheTTL),
+		flights:       &flightGroup{},
+		closer:        newCloser(),
+		maxBodyBytes:  DefaultMaxBodyBytes,
+	}
+	for _, option := range options {
+		option(&rh)
+	}
+	rh.client = rh.replayClient()
+	if rh.customQuery == 0 {
+		rh.title2Query = defaultTitle2Query(rh)
+	}
+
+	return
+}
+
+// Derive returns a copy of the handler customized through options, applied over its configuration: e.g. a handler for another language,
+// through WithLanguage, keeping the client, the User-Agent and the observers of a base one. Unless replaced by options, the copy shares
+// the HTTP client, the rate limiter, the concurrency bound, the negative cache and the circuit breaker of the handler; pages found to be missing
+// are shared only while the copy queries the wiki alike, e.g. not through another WithQueryBuilder or WithVariant. It's closed along with
+// the handler, while closing it leaves the handler, and the other handlers derived from it, open.
+func (rh RequestHandler) Derive(options ...Option) RequestHandler {
+	rh.headers = rh.headers.Clone() //Options may add to them
+	rh.closer = rh.closer.child()
+	for _, option := range options {
+		option(&rh)
+	}
+	rh.client = rh.replayClient()
+	rh.flights = &flightGroup{} //Lookups are shared only by handlers with the same configuration
+	if rh.customQuery == 0 {
+		rh.title2Query = defaultTitle2Query(rh)
+	}
+	return rh
 }
 
-// New loads or creates a RequestHandler for the specified language.
-func New(lang string) (rh RequestHandler) {
-	title2Query := func(title string, life float64) string {
+// DefaultProject is the Wikimedia project targeted by default.
+const DefaultProject = "wikipedia"
+
+// wikiURL returns the base URL of the wiki of the specified project in the specified language.
+func wikiURL(lang, project string) string {
+	return fmt.Sprintf("https://%v.%v.org", lang, project)
+}
+
+// defaultTitle2Query returns the standard query builder for the handler.
+func defaultTitle2Query(rh RequestHandler) func(title string, life float64) string {
+	client, baseURL := rh.client, rh.baseURL
+	extraProps, extraParams := rh.include.fallbackParams()
+	if rh.sectionFormat != "" {
+		extraParams += "&exsectionformat=" + url.QueryEscape(rh.sectionFormat)
+	}
+	formatVersion := rh.formatVersion
+	if formatVersion != 1 {
+		formatVersion = 2
+	}
+	return func(title string, life float64) string {
 		title = underscoreRule.Replace(title)
-		baseURL := ""
+		query := ""
 
 		switch {
-		case life < 0.25: //Fall back API
-			client.CloseIdleConnections() //Soft connction reset
-			baseURL = "https://%v.wikipedia.org/w/api.php?action=query&prop=extracts&exintro=&explaintext=&exchars=512&format=json&formatversion=2&redirects=&titles=%v"
+		case rh.compact: //Metadata only
+			query = "%v/w/api.php?action=query&prop=" + strings.TrimPrefix(extraProps, "|") + extraParams + "&format=json&formatversion=" + fmt.Sprint(formatVersion) + "&errorformat=plaintext&redirects=&titles=%v"
+			title = url.QueryEscape(title)
+		case endpointAt(life) == EndpointFallback || rh.include&fallbackOnly != 0: //Fall back API
+			if life > 0 && endpointAt(life) == EndpointFallback { //Only for retries, not for fall backs chosen by call options or extras: the client is shared
+				client.CloseIdleConnections() //Soft connection reset
+			}
+			query = "%v/w/api.php?action=query&prop=extracts" + extraProps + "&exintro=&explaintext=&exchars=" + fmt.Sprint(extractChars) + extraParams + "&format=json&formatversion=" + fmt.Sprint(formatVersion) + "&errorformat=plaintext&redirects=&titles=%v"
 			title = url.QueryEscape(title)
 		default: //Default API
-			baseURL = "https://%v.wikipedia.org/api/rest_v1/page/summary/%v?redirect=true"
+			query = "%v/api/rest_v1/page/summary/%v?redirect=true"
 			title = url.PathEscape(title)
 		}
 
-		return fmt.Sprintf(baseURL, lang, title)
-	}
-
-	return RequestHandler{
-		title2Query,
+		return fmt.Sprintf(query, baseURL, title)
 	}
 }
 
 var underscoreRule = strings.NewReplacer(" ", "_")
 
+// extractChars is the maximum length of the abstracts requested to the fall back API.
+const extractChars = 512
+
+// truncated checks if abstract has been cut short, either marked with an ellipsis or, if capped to extractChars as by the fall back API, hitting the length limit.
+func truncated(abstract string, capped bool) bool {
+	abstract = strings.TrimSpace(abstract)
+	return strings.HasSuffix(abstract, "...") || strings.HasSuffix(abstract, "…") || (capped && utf8.RuneCountInString(abstract) >= extractChars)
+}
+
+// apiQuery returns the action API query with the specified parameters.
+func (rh RequestHandler) apiQuery(params url.Values) string {
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	params.Set("errorformat", "plaintext")
+	return rh.baseURL + "/w/api.php?" + params.Encode()
+}
+
+// restQuery returns the REST API query for the specified endpoint and title.
+func (rh RequestHandler) restQuery(endpoint, title string) string {
+	return rh.baseURL + "/api/rest_v1/page/" + endpoint + "/" + url.PathEscape(underscoreRule.Replace(title))
+}
+
 // RequestHandler is a hub from which is possible to retrieve informations about Wikipedia articles.
 type RequestHandler struct {
-	title2Query func(title string, life float64) (query string)
+	title2Query         func(title string, life float64) (query string) //life is the share of the retries left, see endpointAt
+	customQuery         uint64                                          //ID of the builder of title2Query set through WithQueryBuilder, 0 if none
+	lang, baseURL       string
+	project             string
+	wikidataURL         string
+	pageviewsURL        string
+	client              *http.Client
+	replay              *replayTransport //Recordings wrapping the transport of client, set through WithReplay, nil means none
+	limiter             *rate.Limiter
+	headers             http.Header //Extra headers for every request
+	userAgent           string      //Empty means left to the transport
+	sectionFormat       string      //Value of exsectionformat, empty means the API default
+	include             Extra       //Extras requested by From
+	compact             bool        //Retrieve only the metadata of pages, see Compact
+	sentenceTruncation  bool        //Trim abstracts to whole sentences
+	cleanExtract        bool        //Strip residual markup from abstracts
+	strictTitleMatch    bool        //Reject replies about pages unrelated to the requested title
+	caseSensitiveTitles bool        //The wiki keeps the case of the first letter of titles
+	retryEmptyExtract   bool        //Query the fall back API for pages whose REST summary has no extract
+	mobile              bool        //Retrieve HTML formatted for mobile devices
+	clock               Clock
+	semaphore           chan struct{} //Bounds in-flight requests, nil means unbounded
+	mainNamespaceOnly   bool
+	attemptTimeout      time.Duration //Timeout of each attempt of From, 0 means none
+	maxAttempts         int           //Maximum number of attempts of From, 0 means as many as the backoff schedule allows
+	maxBackoff          time.Duration //Maximum duration of the retries of From, 0 means depending on the context deadline
+	maxBodyBytes        int64         //Maximum size of reply bodies, non positive means unbounded
+	customMaxBodyBytes  bool          //maxBodyBytes was set through WithMaxBodyBytes
+	formatVersion       int           //JSON format version of the fall back queries of From: 1, or 2 for any other value
+	requestObserver     func(RequestStats)
+	requestTagger       func(ctx context.Context) map[string]string
+	resolutionObserver  func(Resolution)
+	negativeCache       *negativeCache  //Shared by all the copies of the handler, nil means disabled
+	flights             *flightGroup    //Shared by all the copies of the handler
+	breaker             *circuitBreaker //Shared by all the copies of the handler, nil means disabled
+	adaptive            *adaptiveRate   //Shared by all the copies of the handler, nil means a fixed rate
+	closer              *closer         //Shared by all the copies of the handler
+}
+
+// From returns a WikiPage from an article Title, handler defaults may be overridden for this call only through options. It's safe to use concurrently, concurrent calls for the same page share a single lookup.
+// Warning: if the context has a deadline, in the worst case it keeps retrying until then, up to 48 hours; otherwise it gives up after DefaultMaxBackoff, unless overridden through WithMaxBackoff.
+// As such it's advised to setup a timeout with the context: if it expires, or it's cancelled, before a conclusive reply, the cause of the error is ctx.Err(). Titles are queried as they are, apart from spaces turned into underscores: normalization,
+// as the capitalization of the first letter, is left to the wiki. Case-sensitive wikis, such as Wiktionary, need WithCaseSensitiveTitles for titles to be compared correctly.
+func (rh RequestHandler) From(ctx context.Context, title string, options ...CallOption) (WikiPage, error) {
+	l, err := rh.resolve(ctx, title, options...)
+	return l.Page, err
 }
 
-// From returns a WikiPage from an article Title. It's safe to use concurrently. Warning: in the worst case it can block for more than 48 hours. As such it's advised to setup a timeout with the context.
-func (rh RequestHandler) From(ctx context.Context, title string) (p WikiPage, err error) {
-	//Query for page
-	mayMissingPage, err := pageFrom(ctx, rh.title2Query(title, 1))
+// FromRaw is like From, but it returns also the untouched body of the reply the WikiPage was parsed from, e.g. for fields not modeled by WikiPage.
+// On failure it's the body of the last reply, if any, useful to report parsing errors. It's nil for pages remembered as missing.
+func (rh RequestHandler) FromRaw(ctx context.Context, title string, options ...CallOption) (WikiPage, json.RawMessage, error) {
+	l, err := rh.resolve(ctx, title, options...)
+	return l.Page, l.Raw, err
+}
+
+// resolve looks up title as described in From.
+func (rh RequestHandler) resolve(ctx context.Context, title string, options ...CallOption) (l lookup, err error) {
+	rh, c := rh.with(options...)
+
+	if rh.resolutionObserver != nil {
+		start := rh.clock.Now()
+		defer func() {
+			rh.resolutionObserver(newResolution(title, l, rh.clock.Now().Sub(start), err))
+		}()
+	}
+
+	if rh.closer.Closed() { //Before the cache, so that every call after Close fails alike
+		return l, errors.WithStack(ErrClosed)
+	}
+
+	//Check for pages known to be missing
+	cacheKey := rh.queryKey() + "|" + underscoreRule.Replace(title)
+	if rh.negativeCache.Missing(cacheKey, rh.clock.Now()) {
+		l.Endpoint = "cache"
+		return l, errors.WithStack(pageNotFound{title: title, endpoint: "cache"})
+	}
+
+	//Query for page, sharing the lookup with concurrent calls
+	l, err = rh.flights.Do(ctx, fmt.Sprint(cacheKey, "|", c.endpoint), func(ctx context.Context) (lookup, error) {
+		l, err := rh.from(ctx, title)
+		if _, notFound := NotFound(err); notFound {
+			rh.negativeCache.Add(cacheKey, rh.clock.Now())
+		}
+		return l, err
+	})
+
+	if err == nil && rh.mainNamespaceOnly && l.Page.Namespace != 0 {
+		err = errors.WithStack(WrongNamespace{l.Page.Title, l.Page.Namespace})
+		l.Page = WikiPage{}
+	}
+
+	return
+}
+
+// queryKey identifies the queries of From, so that pages found to be missing are shared only by handlers querying alike.
+func (rh RequestHandler) queryKey() string {
+	return fmt.Sprint(rh.baseURL, "|", rh.customQuery, "|", rh.include, "|", rh.compact, "|", rh.sectionFormat, "|", rh.formatVersion, "|", rh.headers.Get("Accept-Language"))
+}
+
+// FromTimeout is a convenience wrapper of From, bounding the call to the specified timeout.
+func (rh RequestHandler) FromTimeout(title string, timeout time.Duration, options ...CallOption) (WikiPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return rh.From(ctx, title, options...)
+}
+
+// lookup is the outcome of the lookup of a page.
+type lookup struct {
+	Page     WikiPage
+	Attempts int
+	Endpoint string //Endpoint of the last reply, if any
+	Raw      []byte //Body of the last reply, if any
+}
+
+// from looks up title, retrying with exponential backoff on failure.
+func (rh RequestHandler) from(ctx context.Context, title string) (l lookup, err error) {
+	ctx, cancel := rh.closer.bind(ctx) //Stop retrying on Close
+	defer cancel()
+
+	l.Page, l.Endpoint, l.Raw, err = rh.attempt(ctx, title, 1)
+	l.Attempts = 1
 
 	if err != nil { //Handle error gracefully
-		deadlines := expDeadlines(ctx, 48*time.Hour) //Exponential backoff deadlines
+		deadlines := expDeadlines(ctx, rh.clock.Now(), rh.backoffFor(ctx), rh.maxAttempts-1) //Exponential backoff deadlines
 		for i, deadline := range deadlines {
-			if err == nil || ctx.Err() != nil {
+			if err == nil || conclusive(err) || ctx.Err() != nil {
 				break
 			}
-			context, cancel := context.WithDeadline(ctx, deadline)
-			<-context.Done()
-			cancel() //Not needed, used just to make happy "go vet"
-			mayMissingPage, err = pageFrom(ctx, rh.title2Query(title, float64(len(deadlines)-i)/float64(len(deadlines))))
+			select {
+			case <-rh.clock.After(deadline.Sub(rh.clock.Now())):
+			case <-ctx.Done():
+				continue
+			}
+			l.Page, l.Endpoint, l.Raw, err = rh.attempt(ctx, title, float64(len(deadlines)-1-i)/float64(len(deadlines))) //The last one queries the fall back API, however many
+			l.Attempts++
 		}
 	}
 
-	//Handle errors
 	switch {
-	case err == nil && mayMissingPage.Missing:
-		err = errors.WithStack(pageNotFound{title})
-	case err != nil:
+	case err == nil, conclusive(err):
 		//Do nothing
+	case rh.closer.Closed():
+		err = errors.WithStack(ErrClosed)
+	case ctx.Err() != nil: //Tell cancellation and timeout apart from inconclusive replies
+		err = errors.Wrapf(ctx.Err(), "lookup of %v aborted after %v attempts, last error: %v", title, l.Attempts, err)
 	default:
-		p = mayMissingPage.WikiPage
+		err = BackoffExhausted{title, l.Attempts, err}
 	}
 
 	return
 }
 
-//Exponential backoff deadlines
-func expDeadlines(ctx context.Context, maxDuration time.Duration) (deadlines []time.Time) {
-	deadline, ok := ctx.Deadline()
-	now := time.Now()
-	if twoDaysFromNow := now.Add(48 * time.Hour); !ok || twoDaysFromNow.Before(deadline) {
-		deadline = twoDaysFromNow
+// conclusive checks if err would happen again on retry: missing pages, interwiki titles, forbidden requests, an open circuit breaker,
+// a reply too large or about an unrelated page.
+func conclusive(err error) bool {
+	_, notFound := NotFound(err)
+	interwiki := isInterwiki(err)
+	_, refused := errors.Cause(err).(Forbidden)
+	_, tooLarge := errors.Cause(err).(ResponseTooLarge)
+	_, mismatch := errors.Cause(err).(TitleMismatch)
+	return notFound || interwiki || refused || tooLarge || mismatch || errors.Cause(err) == ErrCircuitOpen
+}
+
+// attempt queries for title once, within the per attempt timeout if any.
+func (rh RequestHandler) attempt(ctx context.Context, title string, life float64) (WikiPage, string, []byte, error) {
+	if rh.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rh.attemptTimeout)
+		defer cancel()
+	}
+	p, endpoint, body, err := rh.pageFrom(ctx, title, rh.title2Query(title, life))
+	_, notFound := NotFound(err)
+	switch {
+	case err == nil && endpoint == "rest" && p.Abstract == "" && rh.retryEmptyExtract:
+		p = rh.withFallbackExtract(ctx, title, p)
+	case notFound && endpoint == "rest" && strings.Contains(title, ":"):
+		//Only the fall back API reports interwiki titles, which the REST API just doesn't find
+		if _, fallbackEndpoint, fallbackBody, fallbackErr := rh.pageFrom(ctx, title, rh.title2Query(title, 0)); isInterwiki(fallbackErr) {
+			return WikiPage{}, fallbackEndpoint, fallbackBody, fallbackErr
+		}
 	}
+	return p, endpoint, body, err
+}
 
-	db := 10 * time.Second
-	da := deadline.Sub(now) - db
-	deadlines = make([]time.Time, 0, 32)
-	for da > 250*time.Millisecond {
-		deadline = deadline.Add(-db)
-		deadlines = append(deadlines, deadline)
+// isInterwiki checks if err reports an interwiki title.
+func isInterwiki(err error) bool {
+	_, ok := errors.Cause(err).(InterwikiTitle)
+	return ok
+}
 
-		//Exponential backoff: every time delay is halved (average)
-		db = time.Duration(rand.Int63n(int64(da)))
-		da -= db
+// withFallbackExtract returns p with the abstract extracted by the fall back API, if any, or p as it is otherwise.
+func (rh RequestHandler) withFallbackExtract(ctx context.Context, title string, p WikiPage) WikiPage {
+	fallback, endpoint, _, err := rh.pageFrom(ctx, title, rh.title2Query(title, 0))
+	if err != nil || endpoint != "query" || fallback.ID != p.ID || fallback.Abstract == "" { //The empty extract stands
+		return p
 	}
+	p.Abstract, p.AbstractScope, p.Truncated = fallback.Abstract, fallback.AbstractScope, fallback.Truncated
+	return p
+}
 
-	//Reverse
-	for left, right := 0, len(deadlines)-1; left < right; left, right = left+1, right-1 {
-		deadlines[left], deadlines[right] = deadlines[right], deadlines[left]
+// with returns a copy of the handler with the call options applied, along with the resulting configuration.
+func (rh RequestHandler) with(options ...CallOption) (RequestHandler, callConfig) {
+	var c callConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.lang != "" && c.lang != rh.lang {
+		rh.lang, rh.baseURL = c.lang, wikiURL(c.lang, rh.project)
+		if rh.customQuery == 0 {
+			rh.title2Query = defaultTitle2Query(rh)
+		}
+	}
+	if c.compact {
+		c.include |= compactExtras
+	}
+	if c.include != rh.include || c.compact != rh.compact {
+		rh.include, rh.compact = c.include, c.compact
+		if rh.customQuery == 0 {
+			rh.title2Query = defaultTitle2Query(rh)
+		}
+	}
+	if c.endpoint != EndpointAuto {
+		title2Query := rh.title2Query
+		rh.title2Query = func(title string, life float64) string {
+			return title2Query(title, c.endpoint.life(life))
+		}
+	}
+	return rh, c
+}
+
+// DefaultMaxBackoff is how long From keeps retrying a page when the context has no deadline, so that forgetting one doesn't hang the caller for days.
+const DefaultMaxBackoff = 5 * time.Minute
+
+// backoffFor returns how long From keeps retrying a page within ctx.
+func (rh RequestHandler) backoffFor(ctx context.Context) time.Duration {
+	_, ok := ctx.Deadline()
+	switch {
+	case rh.maxBackoff > 0:
+		return rh.maxBackoff
+	case ok:
+		return 48 * time.Hour
+	default:
+		return DefaultMaxBackoff
+	}
+}
+
+// expDeadlines returns the deadlines of the retries within maxDuration, or the context deadline if earlier, keeping the last 10 seconds for the last retry.
+// Waits double at every retry, starting from at least 250ms, and each deadline but the last is brought forward by a random jitter of up to half its wait,
+// so that the number of deadlines depends only on the time available: 8 in a minute, 14 in an hour and 20 in 48 hours.
+// A non negative maxRetries caps the number of deadlines to the first maxRetries, so that zero means no retries at all.
+func expDeadlines(ctx context.Context, now time.Time, maxDuration time.Duration, maxRetries int) (deadlines []time.Time) {
+	deadline, ok := ctx.Deadline()
+	if maxDeadline := now.Add(maxDuration); !ok || maxDeadline.Before(deadline) {
+		deadline = maxDeadline
+	}
+
+	available := deadline.Sub(now) - 10*time.Second
+	retries := 0
+	for wait := available; wait > 250*time.Millisecond; wait /= 2 {
+		retries++
+	}
+
+	n := retries
+	if maxRetries >= 0 && n > maxRetries {
+		n = maxRetries
+	}
+	deadlines = make([]time.Time, n)
+	for i := range deadlines {
+		nominal := available >> uint(retries-1-i) //Waits double: the previous nominal deadline is at half of it
+		if i < retries-1 {
+			nominal -= time.Duration(rand.Int63n(int64(nominal/4) + 1))
+		}
+		deadlines[i] = now.Add(nominal)
 	}
 
 	return
 }
 
-var client = &http.Client{Timeout: 10 * time.Second}
-var limiter = rate.NewLimiter(150, 1)
+// fetch retrieves the body of query, respecting the concurrency bound and the rate limiter.
+func (rh RequestHandler) fetch(ctx context.Context, query string) (body []byte, status int, err error) {
+	body, status, _, err = rh.fetchFinal(ctx, query)
+	return
+}
+
+// fetchFinal is like fetch, but it returns also the URL finally retrieved, after following HTTP redirects.
+func (rh RequestHandler) fetchFinal(ctx context.Context, query string) (body []byte, status int, finalURL string, err error) {
+	resp, done, err := rh.open(ctx, query)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err == nil && status == http.StatusForbidden { //After done, as a refusal isn't a failure of the wiki
+			err = forbidden(body)
+		}
+	}()
+	defer func() { err = done(status, err) }()
+
+	status, reader := resp.StatusCode, io.Reader(resp.Body)
+	if rh.maxBodyBytes > 0 {
+		reader = io.LimitReader(resp.Body, rh.maxBodyBytes+1) //A byte more, to tell if the body exceeds the cap
+	}
+	body, err = ioutil.ReadAll(reader)
+	if err == nil && rh.maxBodyBytes > 0 && int64(len(body)) > rh.maxBodyBytes {
+		return nil, status, "", errors.WithStack(ResponseTooLarge{rh.maxBodyBytes})
+	}
+	if err == nil {
+		err = unfollowedRedirect(resp)
+	}
+	return body, status, resp.Request.URL.String(), err
+}
+
+// open issues the request for query, respecting the concurrency bound and the rate limiter, and returns the response with its body unread.
+// done must be called once the body has been consumed, with the outcome, to release the resources held and report the request:
+// it returns the error of the outcome, turned into ErrClosed if the handler has been closed meanwhile. On failure done has been called already.
+func (rh RequestHandler) open(ctx context.Context, query string) (resp *http.Response, done func(status int, err error) error, err error) {
+	stats := RequestStats{Query: query}
+
+	//Abort on Close as well
+	ctx, cancel := rh.closer.bind(ctx)
+	allowed, holdsSlot, requestStart := false, false, time.Time{}
+	finish := func(status int, err error) error {
+		if resp != nil {
+			//Drain what's left on failure, so that the transport can reuse the connection
+			io.CopyN(ioutil.Discard, resp.Body, maxDrain)
+			resp.Body.Close()
+		}
+		if !requestStart.IsZero() {
+			stats.Duration = rh.clock.Now().Sub(requestStart)
+		}
+		if holdsSlot {
+			<-rh.semaphore
+		}
+		if allowed {
+			rh.breaker.Record(ctx, rh.clock.Now(), status, err)
+		}
+		if err != nil && rh.closer.Closed() {
+			err = errors.WithStack(ErrClosed)
+		}
+		cancel()
+		if rh.requestObserver != nil {
+			stats.Status, stats.Err = status, err
+			rh.requestObserver(stats)
+		}
+		return err
+	}
 
-func pageFrom(ctx context.Context, query string) (p mayMissingPage, err error) {
-	fail := func(e error) (mayMissingPage, error) {
-		p, err = mayMissingPage{}, errors.Wrapf(e, "error with the following query: %v", query)
-		return p, err
+	if err = rh.breaker.Allow(rh.clock.Now()); err != nil {
+		return nil, nil, finish(0, err)
 	}
+	allowed = true
 
 	request, err := http.NewRequestWithContext(ctx, "GET", query, nil)
 	if err != nil {
-		return fail(err)
+		return nil, nil, finish(0, err)
 	}
 	//Set User-Agent as per wikipedia API rules https://en.wikipedia.org/api/rest_v1/#/Page_content
-	request.Header.Set("User-Agent", "[https://github.com/negapedia/wikipage]")
+	if rh.userAgent != "" {
+		request.Header.Set("User-Agent", rh.userAgent)
+	}
+	for key, values := range rh.headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	if rh.requestTagger != nil {
+		for key, value := range rh.requestTagger(ctx) {
+			request.Header.Set(key, value)
+		}
+	}
+
+	//Bound in-flight requests, the slot is released once the body has been read
+	start := rh.clock.Now()
+	if rh.semaphore != nil {
+		select {
+		case rh.semaphore <- struct{}{}:
+			holdsSlot = true
+		case <-ctx.Done():
+			return nil, nil, finish(0, ctx.Err())
+		}
+	}
+	stats.ConcurrencyWait = rh.clock.Now().Sub(start)
 
 	//Respect rate limiter as per wikipedia API rules https://en.wikipedia.org/api/rest_v1/#/Page_content
-	err = limiter.Wait(ctx)
+	start = rh.clock.Now()
+	err = rh.limiter.Wait(ctx)
+	stats.LimiterWait = rh.clock.Now().Sub(start)
 	if err != nil {
-		return fail(err)
+		return nil, nil, finish(0, err)
 	}
 
-	resp, err := client.Do(request)
-	if err != nil {
-		return fail(err)
+	requestStart = rh.clock.Now()
+	if resp, err = rh.client.Do(request); err != nil {
+		resp = nil
+		return nil, nil, finish(0, err)
 	}
-	defer resp.Body.Close()
+	rh.adaptive.Record(resp.StatusCode, resp.Header)
+	return resp, finish, nil
+}
+
+// unfollowedRedirect reports an HTTP redirect left unfollowed by the client as an error.
+func unfollowedRedirect(resp *http.Response) error {
+	if location := resp.Header.Get("Location"); location != "" && resp.StatusCode/100 == 3 {
+		return errors.Errorf("HTTP redirect to %v not followed: the HTTP client must follow redirects, the REST API resolves title redirects through them", location)
+	}
+	return nil
+}
+
+// maxDrain is the maximum number of bytes drained from bodies left unread, beyond it closing the connection is cheaper.
+const maxDrain = 64 << 10
 
-	body, err := ioutil.ReadAll(resp.Body)
+// RequestStats describes a request issued to the API.
+type RequestStats struct {
+	Query           string
+	Status          int           //HTTP status of the reply, 0 if none was received
+	ConcurrencyWait time.Duration //Time spent waiting for an in-flight slot, see WithMaxConcurrency
+	LimiterWait     time.Duration //Time spent waiting on the rate limiter
+	Duration        time.Duration //Time spent on the HTTP request, body read included
+	Err             error
+}
+
+// getJSON fetches query and unmarshals its JSON body into v.
+func (rh RequestHandler) getJSON(ctx context.Context, query string, v interface{}) (err error) {
+	body, _, err := rh.fetch(ctx, query)
+	if err == nil {
+		err = decode(body, v)
+	}
+	return errors.Wrapf(err, "error with the following query: %v", query)
+}
+
+// getREST fetches the REST API query about title and unmarshals its JSON body into v, a 404 reply is reported as a not found error.
+func (rh RequestHandler) getREST(ctx context.Context, title, query string, v interface{}) error {
+	body, err := rh.getRESTBody(ctx, title, query)
+	if err == nil {
+		err = errors.Wrapf(decode(body, v), "error with the following query: %v", query)
+	}
+	return err
+}
+
+// getRESTBody retrieves the body of the REST API query for title, a 404 is reported as a not found error.
+func (rh RequestHandler) getRESTBody(ctx context.Context, title, query string) (body []byte, err error) {
+	body, status, err := rh.fetch(ctx, query)
+	switch {
+	case err != nil:
+		//Do nothing
+	case status == http.StatusNotFound:
+		return nil, errors.WithStack(pageNotFound{title: title, endpoint: "rest", status: status})
+	case status != http.StatusOK:
+		err = errors.Errorf("unexpected status %v", status)
+	}
+	return body, errors.Wrapf(err, "error with the following query: %v", query)
+}
+
+// queryAll issues the action API query with the specified parameters, following continuations: onBody is called on every reply body.
+func (rh RequestHandler) queryAll(ctx context.Context, params url.Values, onBody func(body []byte) error) error {
+	for {
+		query := rh.apiQuery(params)
+		body, _, err := rh.fetch(ctx, query)
+		if err != nil {
+			return errors.Wrapf(err, "error with the following query: %v", query)
+		}
+
+		var data struct {
+			Continue map[string]interface{}
+			apiErrors
+		}
+		if err = decode(body, &data); err != nil {
+			return errors.Wrapf(err, "error with the following query: %v", query)
+		}
+		if apiErr := data.first(); apiErr != nil {
+			return errors.Errorf("error with the following query: %v: %v (%v)", query, apiErr.Info, apiErr.Code)
+		}
+		if err = onBody(body); err != nil {
+			return errors.Wrapf(err, "error with the following query: %v", query)
+		}
+
+		if len(data.Continue) == 0 {
+			return nil
+		}
+		next := url.Values{}
+		for key, values := range params {
+			next[key] = values
+		}
+		for key, value := range data.Continue {
+			next.Set(key, fmt.Sprint(value))
+		}
+		params = next
+	}
+}
+
+// DefaultUserAgent is the User-Agent set by default on every request.
+const DefaultUserAgent = "[https://github.com/negapedia/wikipage]"
+
+// Defaults of the client and limiter shared by RequestHandlers, they may be overridden before creating the first RequestHandler, e.g. in an init function.
+var (
+	DefaultRate    rate.Limit = 150
+	DefaultBurst              = 1
+	DefaultTimeout            = 10 * time.Second
+)
+
+var shared struct {
+	sync.Once
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+// sharedClientAndLimiter returns the client and limiter shared by RequestHandlers, creating them on first use.
+func sharedClientAndLimiter() (*http.Client, *rate.Limiter) {
+	shared.Do(func() {
+		shared.client, shared.limiter = newClientAndLimiter()
+	})
+	return shared.client, shared.limiter
+}
+
+// newClientAndLimiter returns a new client and limiter as configured by the defaults.
+func newClientAndLimiter() (*http.Client, *rate.Limiter) {
+	return &http.Client{Timeout: DefaultTimeout, Transport: DefaultTransport(), CheckRedirect: checkRedirect}, rate.NewLimiter(DefaultRate, DefaultBurst)
+}
+
+// maxHTTPRedirects is the maximum number of HTTP redirects followed for a request, e.g. for host canonicalization and title redirects of the REST API.
+const maxHTTPRedirects = 5
+
+// checkRedirect lets the client follow up to maxHTTPRedirects HTTP redirects.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxHTTPRedirects {
+		return errors.Errorf("stopped after %v HTTP redirects", maxHTTPRedirects)
+	}
+	return nil
+}
+
+// DefaultTransport returns a transport tuned for Wikimedia APIs: HTTP/2 is enabled and plenty of keep-alive connections are retained per host, so that parallel requests reuse them.
+func DefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConns = 256
+	transport.MaxIdleConnsPerHost = 64
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.DisableKeepAlives = false
+	return transport
+}
+
+// pageFrom queries for title, a missing page is reported as a not found error. It returns also the endpoint that replied, if known: "rest" or "query".
+func (rh RequestHandler) pageFrom(ctx context.Context, title, query string) (p WikiPage, endpoint string, body []byte, err error) {
+	fail := func(e error) (WikiPage, string, []byte, error) {
+		p, err = WikiPage{}, errors.Wrapf(e, "error with the following query: %v", query)
+		return p, endpoint, body, err
+	}
+
+	body, status, finalURL, err := rh.fetchFinal(ctx, query)
 	if err != nil {
 		return fail(err)
 	}
+	switch status {
+	case http.StatusOK:
+		//Go on
+	case http.StatusNotFound: //Only the REST API replies so, whatever the body
+		return WikiPage{}, "rest", body, errors.WithStack(pageNotFound{title: title, endpoint: "rest", status: status})
+	default: //Transient failures, as too many requests or server errors, whatever the body
+		return fail(errors.Errorf("unexpected status %v", status))
+	}
 
 	//Marshalling results for two different replies for queries
 	data := struct {
 		//Rest API standard
-		Type string
-		*mayMissingPage
+		Type    string
+		Missing bool
+		restSummary
 
 		//Result for query API
 		Query struct {
-			Pages []mayMissingPage
+			Normalized []struct{ From, To string }
+			Redirects  []struct{ From, To string }
+			Interwiki  []struct{ Title, IW string }
+			Pages      queryPages
 		}
-	}{mayMissingPage: &p}
+		apiErrors
+	}{}
 
-	err = json.Unmarshal(body, &data)
+	err = decode(body, &data)
 	if err != nil {
 		return fail(err)
 	}
+	if apiErr := data.first(); apiErr != nil { //E.g. maxlag or readonly, only a missing title is conclusive
+		return fail(apiErr.asError(title))
+	}
 
 	//Convert data to the expected format
-	for _, p := range data.Query.Pages {
-		*data.mayMissingPage = p
+	p, missing, endpoint := data.WikiPage, data.Missing, "query"
+	p.AbstractScope = "intro"
+	if data.Type != "" {
+		p, endpoint = data.page(), "rest"
 	}
-	if data.Type == "https://mediawiki.org/wiki/HyperSwitch/errors/not_found" || data.ID == 0 {
-		data.mayMissingPage.Missing = true
+	switch len(data.Query.Pages) {
+	case 0:
+	case 1:
+		p, missing = data.Query.Pages[0].page(), data.Query.Pages[0].Missing
+	default:
+		return fail(errors.Errorf("%v pages returned for a single title", len(data.Query.Pages)))
 	}
-	return
+	if len(data.Query.Interwiki) > 0 {
+		iw := data.Query.Interwiki[0]
+		return WikiPage{}, endpoint, body, errors.WithStack(InterwikiTitle{iw.IW, strings.TrimPrefix(iw.Title, iw.IW+":")})
+	}
+	switch {
+	case data.Type == "https://mediawiki.org/wiki/HyperSwitch/errors/not_found" || missing:
+		return WikiPage{}, endpoint, body, errors.WithStack(pageNotFound{title: title, endpoint: endpoint, status: status})
+	case p.ID == 0: //Neither a page nor a confirmation that it's missing
+		return fail(errors.New("reply without a page"))
+	}
+
+	p.RequestedTitle, p.NormalizedTitle = title, title
+	for _, n := range data.Query.Normalized {
+		p.NormalizedTitle = n.To
+	}
+	if rh.strictTitleMatch {
+		hops := map[string]string{}
+		for _, hop := range append(data.Query.Normalized, data.Query.Redirects...) {
+			hops[hop.From] = hop.To
+		}
+		if target, ok := restTitle(finalURL); ok && finalURL != query { //The REST API redirects through HTTP
+			hops[title] = target
+		}
+		if !titleExplained(title, p.Title, hops, rh.caseSensitiveTitles) {
+			return WikiPage{}, endpoint, body, errors.WithStack(TitleMismatch{title, p.Title})
+		}
+	}
+	return rh.finish(rh.compacted(rh.include.keep(rh.localize(derive(p))))), endpoint, body, nil
+}
+
+// finish post-processes the abstract of p as configured, on every path returning a WikiPage, so that it's the same whatever the call.
+func (rh RequestHandler) finish(p WikiPage) WikiPage {
+	if rh.cleanExtract {
+		p.Abstract = cleanExtract(p.Abstract)
+	}
+	if rh.sentenceTruncation {
+		p.Abstract = wholeSentences(p.Abstract)
+	}
+	return p
+}
+
+// derive fills the fields of p which are derived from the others.
+func derive(p WikiPage) WikiPage {
+	p.Truncated = truncated(p.Abstract, p.AbstractScope == "intro") //Only the fall back API caps the length of abstracts
+	if p.DisplayTitle == "" {
+		p.DisplayTitle = p.Title
+	}
+	return p
+}
+
+// restSummary is the page summary object returned by the REST API.
+type restSummary struct {
+	WikiPage
+	RestNamespace struct {
+		ID int
+	} `json:"namespace"`
+	Titles struct {
+		Display string
+	}
+}
+
+func (s restSummary) page() WikiPage {
+	p := s.WikiPage
+	p.Namespace, p.AbstractScope = s.RestNamespace.ID, "summary"
+	if s.Titles.Display != "" {
+		p.DisplayTitle = s.Titles.Display
+	}
+	return derive(p)
 }
 
 type mayMissingPage struct {
 	Missing bool
 	WikiPage
+
+	//Extras as reported by the fall back API
+	Coordinates []Coordinates `json:"coordinates"`
+	Pageprops   map[string]json.RawMessage
+}
+
+// queryPages are the pages of a reply of the fall back API.
+type queryPages []mayMissingPage
+
+// UnmarshalJSON accepts pages both as an array, as in formatversion=2, and as an object keyed by page ID, as in formatversion=1,
+// whose flags are empty strings instead of booleans.
+func (pages *queryPages) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]mayMissingPage)(pages))
+	}
+
+	var ID2Page map[string]struct {
+		mayMissingPage
+		Missing json.RawMessage
+	}
+	if err := json.Unmarshal(data, &ID2Page); err != nil {
+		return err
+	}
+	for _, p := range ID2Page {
+		p.mayMissingPage.Missing = len(p.Missing) > 0 && string(p.Missing) != "false"
+		*pages = append(*pages, p.mayMissingPage)
+	}
+	return nil
+}
+
+func (m mayMissingPage) page() WikiPage {
+	p := m.WikiPage
+	p.AbstractScope = "intro"
+	if len(m.Coordinates) > 0 {
+		p.Coordinates = m.Coordinates[0]
+	}
+	if len(m.Pageprops) > 0 {
+		//Page properties are mostly strings, the others are kept as raw JSON
+		p.PageProps = make(map[string]string, len(m.Pageprops))
+		for name, raw := range m.Pageprops {
+			var value string
+			if json.Unmarshal(raw, &value) != nil {
+				value = string(raw)
+			}
+			p.PageProps[name] = value
+		}
+	}
+	p.WikibaseItem = p.PageProps["wikibase_item"]
+	if displayTitle := p.PageProps["displaytitle"]; displayTitle != "" {
+		p.DisplayTitle = displayTitle
+	}
+	return p
 }
 
 type pageNotFound struct {
-	title string
+	title, endpoint string
+	status          int
+	id              PageID //Set instead of title for lookups by ID
 }
 
 func (err pageNotFound) Error() string {
+	if err.id != 0 {
+		return fmt.Sprintf("page with ID %v wasn't found", err.id)
+	}
 	return fmt.Sprintf("%v wasn't found", err.title)
 }
 
-// NotFound checks if current error was issued by a page not found, if so it returns page ID and sets "ok" true, otherwise "ok" is false.
-func NotFound(err error) (title string, ok bool) {
+// InterwikiTitle is the error returned by From for titles referring to a page of another wiki through an interwiki prefix,
+// e.g. "fr:Anarchisme": the page isn't local, but it may well exist. Only the fall back API recognizes them: when the REST API doesn't find
+// a title with a prefix, From asks the fall back API as well before concluding that the page is missing.
+// When Prefix is a language code the page can be retrieved through From(ctx, err.Title, WithLang(err.Prefix)).
+type InterwikiTitle struct {
+	Prefix string //Interwiki prefix of the wiki, e.g. "fr"
+	Title  string //Title of the page on that wiki, e.g. "Anarchisme"
+}
+
+func (err InterwikiTitle) Error() string {
+	return fmt.Sprintf("%v:%v is an interwiki reference to a page of another wiki", err.Prefix, err.Title)
+}
+
+// apiErrors are the error objects returned by the action API, in either error format.
+type apiErrors struct {
+	Error  *apiError  //errorformat=bc, the default
+	Errors []apiError //Any other errorformat
+}
+
+// first returns the first error reported, if any.
+func (e apiErrors) first() *apiError {
+	switch {
+	case e.Error != nil:
+		return e.Error
+	case len(e.Errors) > 0:
+		first := e.Errors[0]
+		if first.Info == "" {
+			first.Info = first.Text
+		}
+		return &first
+	default:
+		return nil
+	}
+}
+
+// asError converts the first API error, if any, into a go error.
+func (e apiErrors) asError(title string) error {
+	return e.first().asError(title)
+}
+
+// apiError is the error object returned by the action API.
+type apiError struct {
+	Code, Info string
+	Text       string //Info for errorformat other than bc
+}
+
+// asError converts the API error, if any, into a go error.
+func (e *apiError) asError(title string) error {
+	switch {
+	case e == nil:
+		return nil
+	case e.Code == "missingtitle":
+		return errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	default:
+		return errors.Errorf("%v: %v (%v)", title, e.Info, e.Code)
+	}
+}
+
+// WrongNamespace is the error returned for pages outside the main (article) namespace when WithMainNamespaceOnly is in use.
+type WrongNamespace struct {
+	Title     string
+	Namespace int
+}
+
+func (err WrongNamespace) Error() string {
+	return fmt.Sprintf("%v belongs to namespace %v instead of the main one", err.Title, err.Namespace)
+}
+
+// NotFoundDetails describes how a page was found to be missing.
+type NotFoundDetails struct {
+	Title string //Empty for lookups by ID
+	ID    PageID //0 for lookups by title
+	//Endpoint that confirmed the page as missing: "rest", "query", "cache" for pages remembered as missing or "wikidata" for items without an article.
+	Endpoint string
+	//HTTP status of the reply that confirmed the page as missing, either 200 or 404, 0 if unknown as for pages remembered as missing.
+	Status int
+}
+
+// NotFoundDetailsOf checks if current error was issued by a page not found, if so it returns the details and sets "ok" true, otherwise "ok" is false.
+// Pages are reported as not found only when missing is confirmed by the API, From reports giving up as BackoffExhausted instead.
+func NotFoundDetailsOf(err error) (details NotFoundDetails, ok bool) {
 	pnf, ok := errors.Cause(err).(pageNotFound)
 	if ok {
+		details = NotFoundDetails{Title: pnf.title, ID: pnf.id, Endpoint: pnf.endpoint, Status: pnf.status}
+	}
+	return
+}
+
+// BackoffExhausted is the error returned by From when it gives up on a page, after all attempts yielded inconclusive results.
+type BackoffExhausted struct {
+	Title    string
+	Attempts int
+	Err      error //Last error
+}
+
+func (err BackoffExhausted) Error() string {
+	return fmt.Sprintf("gave up on %v after %v attempts: %v", err.Title, err.Attempts, err.Err)
+}
+
+// Unwrap returns the last error.
+func (err BackoffExhausted) Unwrap() error {
+	return err.Err
+}
+
+// Temporary reports that the failure may not happen again in a later call.
+func (err BackoffExhausted) Temporary() bool {
+	return true
+}
+
+// MalformedResponse is the error returned for replies whose body can't be parsed, as the ones truncated by a dropped connection.
+// From retries on it, as on any other inconclusive reply.
+type MalformedResponse struct {
+	Err error //Parsing error
+}
+
+func (err MalformedResponse) Error() string {
+	return fmt.Sprintf("malformed response: %v", err.Err)
+}
+
+// Unwrap returns the parsing error.
+func (err MalformedResponse) Unwrap() error {
+	return err.Err
+}
+
+// Temporary reports that the failure may not happen again in a later call.
+func (err MalformedResponse) Temporary() bool {
+	return true
+}
+
+// DefaultMaxBodyBytes is the default maximum size of the reply bodies read by a RequestHandler. It doesn't apply to the calls retrieving
+// whole articles, HTML, FullText, FullTextReader and Wikitext, whose replies may be larger for long articles.
+const DefaultMaxBodyBytes = 4 << 20
+
+// fullContent returns the handler for calls retrieving whole articles, which are bounded only by a cap set through WithMaxBodyBytes.
+func (rh RequestHandler) fullContent() RequestHandler {
+	if !rh.customMaxBodyBytes {
+		rh.maxBodyBytes = 0
+	}
+	return rh
+}
+
+// ResponseTooLarge is the error returned for replies whose body exceeds the maximum size set through WithMaxBodyBytes, without reading it further.
+// From doesn't retry on it, as it would happen again.
+type ResponseTooLarge struct {
+	Limit int64 //Maximum size in bytes
+}
+
+func (err ResponseTooLarge) Error() string {
+	return fmt.Sprintf("response body larger than %v bytes", err.Limit)
+}
+
+// Forbidden is the error returned for requests refused with HTTP 403, typically because the User-Agent has been blocked, or it lacks
+// contact information as required by the Wikimedia User-Agent policy: retrying wouldn't help, so From gives up right away.
+type Forbidden struct {
+	Reason string //Plain text excerpt of the explanation in the reply, HTML or not
+}
+
+func (err Forbidden) Error() string {
+	return fmt.Sprintf("request forbidden (HTTP 403), check that the User-Agent identifies the client with contact information: %v", err.Reason)
+}
+
+// maxReasonLength is the maximum length in runes of the excerpt kept as Forbidden.Reason.
+const maxReasonLength = 500
+
+var scriptRule = regexp.MustCompile(`(?is)<(?:script|style|head)\b.*?</(?:script|style|head)>`)
+
+// forbidden returns the Forbidden error for the body of a 403 reply.
+func forbidden(body []byte) error {
+	text := tagRule.ReplaceAllString(scriptRule.ReplaceAllString(string(body), " "), " ")
+	reason := []rune(strings.TrimSpace(spacesRule.ReplaceAllString(html.UnescapeString(text), " ")))
+	if len(reason) > maxReasonLength {
+		reason = append(reason[:maxReasonLength-1], '…')
+	}
+	return errors.WithStack(Forbidden{string(reason)})
+}
+
+// decode parses the JSON body into v, reporting a body that can't be parsed as MalformedResponse.
+func decode(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.WithStack(MalformedResponse{err})
+	}
+	return nil
+}
+
+// NotFound checks if current error was issued by a page not found, if so it returns page title and sets "ok" true, otherwise "ok" is false.
+// For lookups by ID, such as FromIDs, the title is the ID in decimal form: NotFoundID tells them apart.
+func NotFound(err error) (title string, ok bool) {
+	pnf, ok := errors.Cause(err).(pageNotFound)
+	switch {
+	case !ok:
+		//Do nothing
+	case pnf.id != 0:
+		title = pnf.id.String()
+	default:
 		title = pnf.title
 	}
 	return
 }
+
+// NotFoundID checks if current error was issued by a page not found in a lookup by ID, such as FromIDs, if so it returns page ID and sets "ok" true,
+// otherwise "ok" is false, also for pages not found in lookups by title.
+func NotFoundID(err error) (ID PageID, ok bool) {
+	pnf, ok := errors.Cause(err).(pageNotFound)
+	if ok && pnf.id == 0 {
+		ok = false
+	}
+	return pnf.id, ok
+}
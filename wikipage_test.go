@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -21,7 +26,7 @@ const (
 )
 
 func TestUnit(t *testing.T) {
-	pageID, title := uint32(12), "Anarchism"
+	pageID, title := PageID(12), "Anarchism"
 	rh := New("en")
 	p, err := rh.From(context.Background(), title)
 	switch {
@@ -47,8 +52,8 @@ func TestPageFrom(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
 	defer cancel()
 	for _, life := range []float64{1., 0.} {
-		pageID, title := uint32(12), "Anarchism"
-		p, err := pageFrom(ctx, rh.title2Query(title, life))
+		pageID, title := PageID(12), "Anarchism"
+		p, _, _, err := rh.pageFrom(ctx, title, rh.title2Query(title, life))
 		rh.From(ctx, title)
 		switch {
 		case err != nil:
@@ -58,12 +63,197 @@ func TestPageFrom(t *testing.T) {
 		case p.Title != title:
 			t.Error("ageFrom(", title, ",", life, ") returns info for", p.Title)
 		}
-		p, err = pageFrom(ctx, rh.title2Query("0test1test2test3", life))
-		if !p.Missing {
-			t.Error("pageFrom(", title, ",", life, ") returns should be flagged as missing, instead it returns", p)
+		p, _, _, err = rh.pageFrom(ctx, "0test1test2test3", rh.title2Query("0test1test2test3", life))
+		if _, ok := NotFound(err); !ok {
+			t.Error("pageFrom(", title, ",", life, ") should return a not found error, instead it returns", p, err)
 		}
 	}
 }
+func TestNormalizedTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"normalized":[{"fromencoded":false,"from":"anarchism","to":"Anarchism"}],"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+	p, err := rh.From(context.Background(), "anarchism")
+	switch {
+	case err != nil:
+		t.Error("From returns ", err)
+	case p.Title != "Anarchism" || p.RequestedTitle != "anarchism" || p.NormalizedTitle != "Anarchism":
+		t.Error("From returns", p)
+	}
+}
+
+func TestNotFoundStatus(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "<html><body>Not Found</body></html>")
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+	_, err := rh.From(context.Background(), "Atlantis")
+	if details, ok := NotFoundDetailsOf(err); !ok || details.Status != http.StatusNotFound {
+		t.Error("From should report the page as not found, instead it returns", err)
+	}
+	if requests != 1 {
+		t.Error("From shouldn't retry on not found, instead it issued", requests, "requests")
+	}
+}
+
+func TestInterwiki(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"interwiki":[{"title":"fr:Anarchisme","iw":"fr"}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+	_, err := rh.From(context.Background(), "fr:Anarchisme")
+	if iw, ok := errors.Cause(err).(InterwikiTitle); !ok || iw != (InterwikiTitle{"fr", "Anarchisme"}) {
+		t.Error("From should report the interwiki reference, instead it returns", err)
+	}
+	if _, notFound := NotFound(err); notFound {
+		t.Error("Interwiki references shouldn't be reported as not found")
+	}
+}
+
+func TestInterwikiREST(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch title := r.URL.Query().Get("titles"); {
+		case strings.HasPrefix(r.URL.Path, "/api/rest_v1/page/summary/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found","title":"Not found."}`)
+		case title == "fr:Anarchisme":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"interwiki":[{"title":"fr:Anarchisme","iw":"fr"}]}}`)
+		default:
+			fmt.Fprintf(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":%q,"missing":true}]}}`, title)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithBaseURL(server.URL))
+	_, err := rh.From(context.Background(), "fr:Anarchisme")
+	if iw, ok := errors.Cause(err).(InterwikiTitle); !ok || iw != (InterwikiTitle{"fr", "Anarchisme"}) {
+		t.Error("From should report the interwiki reference found by the fall back API, instead it returns", err)
+	}
+	for _, title := range []string{"Missing", "Talk:Missing"} {
+		if _, err = rh.From(context.Background(), title); err == nil {
+			t.Error("From should fail on", title)
+		} else if _, notFound := NotFound(err); !notFound {
+			t.Error("From", title, "returns", err, "expected not found")
+		}
+	}
+}
+
+func TestFromRaw(t *testing.T) {
+	const body = `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy.","experimental":{"score":0.9}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+	switch p, raw, err := rh.FromRaw(context.Background(), "Anarchism"); {
+	case err != nil:
+		t.Error("FromRaw returns", err)
+	case p.Title != "Anarchism" || string(raw) != body:
+		t.Error("FromRaw returns", p, string(raw))
+	}
+}
+
+func TestMultiplePages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."},{"pageid":25,"ns":0,"title":"Autism","extract":"Autism is a developmental disorder."}]}}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxAttempts(1))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+	if p, err := rh.From(context.Background(), "Anarchism"); err == nil {
+		t.Error("From should fail when more than one page is returned, instead it returns", p)
+	}
+}
+
+func TestMalformedResponse(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 { //Connection cut mid-stream
+			fmt.Fprint(w, `{"type":"standard","title":"Rome","pageid":45,"extract":"Rome is the cap`)
+			return
+		}
+		fmt.Fprint(w, `{"type":"standard","title":"Rome","pageid":45,"extract":"Rome is the capital city of Italy."}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithClock(&fakeClock{now: time.Now()}), WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	_, err := rh.From(context.Background(), "Rome")
+	if exhausted, ok := errors.Cause(err).(BackoffExhausted); !ok {
+		t.Error("From should give up on a malformed response, instead it returns", err)
+	} else if _, ok := errors.Cause(exhausted.Err).(MalformedResponse); !ok {
+		t.Error("From should report a malformed response, instead it reports", exhausted.Err)
+	}
+
+	if p, err := rh.From(context.Background(), "Rome"); err != nil || p.Title != "Rome" {
+		t.Error("From should retrieve the page, instead it returns", p, err)
+	}
+}
+
+func TestExpDeadlines(t *testing.T) {
+	now := time.Now()
+	for duration, expected := range map[time.Duration]int{time.Minute: 8, time.Hour: 14, 48 * time.Hour: 20} {
+		ctx, cancel := context.WithDeadline(context.Background(), now.Add(duration))
+		deadlines := expDeadlines(ctx, now, 48*time.Hour, -1)
+		cancel()
+
+		if len(deadlines) != expected {
+			t.Error("For", duration, "expected", expected, "deadlines, got", len(deadlines))
+			continue
+		}
+		available, previous := duration-10*time.Second, now
+		for i, deadline := range deadlines {
+			nominal := now.Add(available >> uint(len(deadlines)-1-i))
+			switch {
+			case !deadline.After(previous):
+				t.Error("For", duration, "deadline", i, "isn't after the previous one")
+			case deadline.After(nominal) || deadline.Before(nominal.Add(-nominal.Sub(now)/4)):
+				t.Error("For", duration, "deadline", i, "is", deadline.Sub(now), "after now, expected about", nominal.Sub(now))
+			}
+			previous = deadline
+		}
+		if last := deadlines[len(deadlines)-1]; !last.Equal(now.Add(available)) {
+			t.Error("For", duration, "the last deadline should leave 10 seconds, instead it's", last.Sub(now), "after now")
+		}
+	}
+
+	for maxRetries, expected := range map[int]int{-1: 20, 0: 0, 3: 3, 100: 20} {
+		if deadlines := expDeadlines(context.Background(), now, 48*time.Hour, maxRetries); len(deadlines) != expected {
+			t.Error("With", maxRetries, "max retries expected", expected, "deadlines, got", len(deadlines))
+		} else if expected > 0 && deadlines[0].Sub(now) > time.Second {
+			t.Error("With", maxRetries, "max retries the first deadline should be the earliest, got", deadlines[0].Sub(now))
+		}
+	}
+}
+
 func TestFrom(t *testing.T) {
 	rh := New("mytest")
 	rh.title2Query = func(title string, life float64) string {
@@ -80,15 +270,16 @@ func TestFrom(t *testing.T) {
 			defer cancel()
 			wikipage, err := rh.From(ctx, fmt.Sprint(pageID))
 			wikipageCheck, ok := generatePage(pageID)
+			wikipageCheck.RequestedTitle, wikipageCheck.NormalizedTitle = fmt.Sprint(pageID), fmt.Sprint(pageID)
 			switch {
 			case err != nil && ok:
 				t.Error("For", pageID, "expected", wikipageCheck, "got", err.Error())
 			case err != nil: // && !ok:
 				if _, IsNotFoundErr := NotFound(err); !IsNotFoundErr {
-					t.Error("For", pageID, "expected", pageNotFound{fmt.Sprint(pageID)}.Error(), "got", err.Error())
+					t.Error("For", pageID, "expected", pageNotFound{title: fmt.Sprint(pageID)}.Error(), "got", err.Error())
 				}
 			default:
-				if wikipage != wikipageCheck {
+				if !reflect.DeepEqual(wikipage, wikipageCheck) {
 					t.Error("For", pageID, "expected", wikipage, "got", wikipageCheck)
 				}
 			}
@@ -112,6 +303,106 @@ func TestFrom(t *testing.T) {
 	}
 }
 
+func TestMaxConcurrency(t *testing.T) {
+	const maxConcurrency = 3
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for m := atomic.LoadInt32(&maxInFlight); n > m && !atomic.CompareAndSwapInt32(&maxInFlight, m, n); m = atomic.LoadInt32(&maxInFlight) {
+		}
+		time.Sleep(20 * time.Millisecond)
+		p, _ := generatePage(1)
+		json.NewEncoder(w).Encode(p)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxConcurrency(maxConcurrency))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?pageids=" + title
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+			defer cancel()
+			if _, err := rh.From(ctx, "1"); err != nil {
+				t.Error("From returns ", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > maxConcurrency {
+		t.Error("Expected at most", maxConcurrency, "requests in flight, got", maxInFlight)
+	}
+}
+
+func TestMainNamespaceOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":2,"ns":1,"title":"Talk:Anarchism","extract":""}]}}`)
+	}))
+	defer server.Close()
+
+	for _, mainNamespaceOnly := range []bool{false, true} {
+		var options []Option
+		if mainNamespaceOnly {
+			options = append(options, WithMainNamespaceOnly())
+		}
+		rh := New("mytest", options...)
+		rh.title2Query = func(title string, life float64) string {
+			return server.URL + "?titles=" + title
+		}
+
+		p, err := rh.From(context.Background(), "Talk:Anarchism")
+		_, isWrongNamespace := errors.Cause(err).(WrongNamespace)
+		switch {
+		case mainNamespaceOnly && !isWrongNamespace:
+			t.Error("From should return a WrongNamespace error, instead it returns", p, err)
+		case !mainNamespaceOnly && err != nil:
+			t.Error("From returns ", err)
+		case !mainNamespaceOnly && p.Namespace != 1:
+			t.Error("From returns namespace", p.Namespace, "expected", 1)
+		}
+	}
+}
+
+func TestCallOptions(t *testing.T) {
+	rh := New("en")
+	if query := rh.title2Query("Anarchism", 1); !strings.HasPrefix(query, "https://en.wikipedia.org/api/rest_v1/") {
+		t.Error("Unexpected default query", query)
+	}
+
+	var lives []float64
+	rh.title2Query = func(title string, life float64) string {
+		lives = append(lives, life)
+		return "http://" + address + "?pageids=" + title
+	}
+	if _, err := rh.From(context.Background(), "1", ForceFallback()); err != nil {
+		t.Error("From returns ", err)
+	}
+	for _, life := range lives {
+		if life != 0 {
+			t.Error("ForceFallback should query with life 0, got", life)
+		}
+	}
+
+	if rh, _ := rh.with(WithLang("de")); !strings.HasPrefix(rh.title2Query("Anarchie", 1), "https://de.wikipedia.org/") {
+		t.Error("WithLang should query the specified language, got", rh.title2Query("Anarchie", 1))
+	}
+
+	rh = New("en", WithProject("wiktionary"))
+	if query := rh.title2Query("anarchy", 1); !strings.HasPrefix(query, "https://en.wiktionary.org/api/rest_v1/") {
+		t.Error("WithProject should query the specified project, got", query)
+	}
+	if rh, _ := rh.with(WithLang("de")); !strings.HasPrefix(rh.title2Query("Anarchie", 1), "https://de.wiktionary.org/") {
+		t.Error("WithLang should keep the handler project, got", rh.title2Query("Anarchie", 1))
+	}
+}
+
 const address = ":8080"
 
 func TestMain(m *testing.M) {
@@ -149,9 +440,697 @@ func generatePage(pageID uint32) (wp WikiPage, ok bool) {
 	if pageID%7 == 0 {
 		return
 	}
-	return WikiPage{pageID, stringFrom(int(pageID) / 10), stringFrom(int(pageID))}, true
+	title, abstract := stringFrom(int(pageID)/10), stringFrom(int(pageID))
+	return WikiPage{ID: PageID(pageID), Title: title, Abstract: abstract, Truncated: len(abstract) >= extractChars, AbstractScope: "intro", Lang: "mytest", Dir: "ltr", DisplayTitle: title}, true
 }
 
 func stringFrom(ID int) string {
 	return "ba" + strings.Repeat("na", ID)
 }
+
+func TestRequestObserver(t *testing.T) {
+	var stats []RequestStats
+	rh := New("mytest", WithRequestObserver(func(s RequestStats) {
+		stats = append(stats, s)
+	}))
+	rh.title2Query = func(title string, life float64) string {
+		return "http://" + address + "?pageids=" + title
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+	rh.From(ctx, "1")
+	switch {
+	case len(stats) == 0:
+		t.Error("Observer wasn't called")
+	case stats[len(stats)-1].Status != http.StatusOK:
+		t.Error("Observer got", stats[len(stats)-1])
+	case stats[len(stats)-1].Duration <= 0:
+		t.Error("Observer got no request duration", stats[len(stats)-1])
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		p, _ := generatePage(1)
+		json.NewEncoder(w).Encode(p)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithHeader("Authorization", "Bearer 0123"), WithHeader("Referer", "https://example.org"), WithVariant("zh-hans"))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?pageids=" + title
+	}
+	if _, err := rh.From(context.Background(), "1"); err != nil {
+		t.Error("From returns ", err)
+	}
+	for key, value := range map[string]string{"Authorization": "Bearer 0123", "Referer": "https://example.org", "Accept-Language": "zh-hans", "User-Agent": DefaultUserAgent} {
+		if header.Get(key) != value {
+			t.Error("Header", key, "is", header.Get(key), "expected", value)
+		}
+	}
+
+	rh = New("mytest", WithUserAgent(""), WithHTTPClient(&http.Client{Transport: userAgentTransport{}}))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?pageids=" + title
+	}
+	if _, err := rh.From(context.Background(), "1"); err != nil {
+		t.Error("From returns ", err)
+	}
+	if userAgent := header.Get("User-Agent"); userAgent != "transport" {
+		t.Error("User-Agent should be left to the transport, got", userAgent)
+	}
+}
+
+type tenantKey struct{}
+
+func TestRequestTagger(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		p, _ := generatePage(1)
+		json.NewEncoder(w).Encode(p)
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithHeader("X-Tenant", "default"), WithRequestTagger(func(ctx context.Context) map[string]string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return map[string]string{"X-Tenant": tenant, "X-Group": "negapedia"}
+	}))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?pageids=" + title
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if _, err := rh.From(ctx, "1"); err != nil {
+		t.Error("From returns ", err)
+	}
+	for key, value := range map[string]string{"X-Tenant": "acme", "X-Group": "negapedia", "User-Agent": DefaultUserAgent} {
+		if header.Get(key) != value {
+			t.Error("Header", key, "is", header.Get(key), "expected", value)
+		}
+	}
+}
+
+type userAgentTransport struct{}
+
+func (userAgentTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Header.Get("User-Agent") == "" {
+		r.Header.Set("User-Agent", "transport")
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	var requests int32
+	unstuck := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 { //The first attempt gets stuck
+			<-unstuck
+			return
+		}
+		p, _ := generatePage(1)
+		json.NewEncoder(w).Encode(p)
+	}))
+	defer server.Close()
+	defer close(unstuck)
+
+	rh := New("mytest", WithAttemptTimeout(50*time.Millisecond))
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?pageids=" + title
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+	start := time.Now()
+	if _, err := rh.From(ctx, "1"); err != nil {
+		t.Error("From returns ", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Error("The stuck attempt should fail fast, instead From took", elapsed)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	for abstract, expected := range map[string]bool{
+		"Anarchism is a political philosophy.":     false,
+		"Anarchism is a political philosophy and…": true,
+		"Anarchism is a political philosophy...\n": true,
+		strings.Repeat("a", extractChars):          true,
+	} {
+		if truncated(abstract, true) != expected {
+			t.Error("truncated(", abstract, ") should return", expected)
+		}
+	}
+	if truncated(strings.Repeat("a", extractChars), false) {
+		t.Error("Abstracts not capped, as REST summaries, shouldn't be deemed truncated because of their length")
+	}
+	if !truncated("Anarchism is a political philosophy and…", false) {
+		t.Error("Abstracts not capped should be deemed truncated when marked with an ellipsis")
+	}
+
+	long := strings.Repeat("Anarchism is a political philosophy. ", 20)
+	rest := restSummary{WikiPage: WikiPage{ID: 12, Title: "Anarchism", Abstract: long}}
+	if p := rest.page(); p.Truncated {
+		t.Error("Long REST summaries shouldn't be deemed truncated")
+	}
+	fallback := mayMissingPage{WikiPage: WikiPage{ID: 12, Title: "Anarchism", Abstract: long}}
+	if p := derive(fallback.page()); !p.Truncated {
+		t.Error("Fall back extracts hitting the length cap should be deemed truncated")
+	}
+}
+
+func TestAbstractScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("titles") != "" {
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		if life < 0.25 {
+			return server.URL + "?titles=" + title
+		}
+		return server.URL + "/" + title
+	}
+	for expected, options := range map[string][]CallOption{"summary": nil, "intro": {ForceFallback()}} {
+		switch p, err := rh.From(context.Background(), "Anarchism", options...); {
+		case err != nil:
+			t.Error("From returns ", err)
+		case p.AbstractScope != expected:
+			t.Error("From returns", p, "expected scope", expected)
+		}
+	}
+}
+
+func TestCaseSensitiveTitles(t *testing.T) {
+	rh := New("en", WithProject("wiktionary"))
+	for _, life := range []float64{1, 0} {
+		if query := rh.title2Query("iPhone", life); !strings.Contains(query, "/iPhone") && !strings.Contains(query, "=iPhone") {
+			t.Error("Titles should be queried as they are, got", query)
+		}
+	}
+}
+
+func TestAbstractHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy.","extract_html":"<p><b>Anarchism</b> is a political philosophy.</p>"}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	switch p, err := rh.From(context.Background(), "Anarchism"); {
+	case err != nil:
+		t.Error("From returns ", err)
+	case p.Abstract != "Anarchism is a political philosophy." || p.AbstractHTML != "<p><b>Anarchism</b> is a political philosophy.</p>":
+		t.Error("From returns", p)
+	}
+}
+
+func TestHTTPRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/rest_v1/page/summary/USA":
+			http.Redirect(w, r, "/api/rest_v1/page/summary/United_States", http.StatusFound)
+		case "/api/rest_v1/page/summary/United_States":
+			fmt.Fprint(w, `{"type":"standard","title":"United States","pageid":3434750,"namespace":{"id":0},"extract":"The United States of America is a country primarily located in North America."}`)
+		default:
+			http.Redirect(w, r, r.URL.Path, http.StatusMovedPermanently)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if p, err := rh.From(context.Background(), "USA"); err != nil || p.Title != "United States" {
+		t.Error("From should follow HTTP redirects, instead it returns", p, err)
+	}
+	if _, err := rh.From(context.Background(), "Loop"); err == nil || !strings.Contains(err.Error(), "HTTP redirects") {
+		t.Error("From should stop following HTTP redirects, instead it returns", err)
+	}
+
+	rh = New("mytest", WithMaxAttempts(1), WithHTTPClient(&http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if _, err := rh.From(context.Background(), "USA"); err == nil || !strings.Contains(err.Error(), "not followed") {
+		t.Error("From should report HTTP redirects not followed, instead it returns", err)
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprintf(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"%v"}`, strings.Repeat("a", 1000))
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithMaxBodyBytes(512))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	_, err := rh.From(context.Background(), "Anarchism")
+	if tooLarge, ok := errors.Cause(err).(ResponseTooLarge); !ok || tooLarge.Limit != 512 {
+		t.Error("From should return a ResponseTooLarge error, instead it returns", err)
+	}
+	if requests != 1 {
+		t.Error("From shouldn't retry on replies too large, instead it issued", requests, "requests")
+	}
+
+	rh = New("mytest", WithMaxBodyBytes(0))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if p, err := rh.From(context.Background(), "Anarchism"); err != nil || len(p.Abstract) != 1000 {
+		t.Error("From returns", p, err)
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("formatversion") != "1" || q.Get("errorformat") != "plaintext" {
+			t.Error("Unexpected query", r.URL)
+		}
+		switch q.Get("titles") {
+		case "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":"","query":{"pages":{"12":{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}}}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":"","query":{"pages":{"-1":{"ns":0,"title":"0test1test2test3","missing":""}}}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithFormatVersion(1), WithMaxAttempts(1))
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	if p, err := rh.From(context.Background(), "Anarchism", ForceFallback()); err != nil || p.ID != 12 || p.Abstract != "Anarchism is a political philosophy." {
+		t.Error("From returns", p, err)
+	}
+	if _, err := rh.From(context.Background(), "0test1test2test3", ForceFallback()); err == nil {
+		t.Error("From should return an error")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("From returns an unexpected error", err)
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("errorformat") != "plaintext" {
+			t.Error("Unexpected query", r.URL)
+		}
+		fmt.Fprint(w, `{"errors":[{"code":"badvalue","text":"Unrecognized value for parameter \"list\": allpages.","module":"main"}],"docref":"See /w/api.php for API usage."}`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	if _, err := rh.Exists(context.Background(), "Anarchism"); err == nil || !strings.Contains(err.Error(), `Unrecognized value for parameter "list": allpages. (badvalue)`) {
+		t.Error("Exists should return the API error, instead it returns", err)
+	}
+}
+
+func TestContextErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done() //The API hangs
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.title2Query = func(title string, life float64) string {
+		return server.URL + "?titles=" + title
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := rh.From(ctx, "Anarchism")
+	if _, notFound := NotFound(err); notFound || errors.Cause(err) != context.DeadlineExceeded {
+		t.Error("From should return the context error on timeout, instead it returns", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	_, err = rh.From(ctx, "Anarchism")
+	if _, notFound := NotFound(err); notFound || errors.Cause(err) != context.Canceled {
+		t.Error("From should return the context error on cancellation, instead it returns", err)
+	}
+}
+
+func TestDerive(t *testing.T) {
+	base := New("en", WithHeader("X-Base", "1"), WithMaxConcurrency(2))
+	rh := base.Derive(WithLanguage("fr"), WithHeader("X-Derived", "1"))
+	switch {
+	case rh.client != base.client || rh.limiter != base.limiter || rh.semaphore != base.semaphore || rh.negativeCache != base.negativeCache:
+		t.Error("Derive should share the client, the limiter, the concurrency bound and the negative cache")
+	case rh.lang != "fr" || rh.baseURL != "https://fr.wikipedia.org" || !strings.HasPrefix(rh.title2Query("Anarchisme", 1), "https://fr.wikipedia.org/"):
+		t.Error("Derive should target the French Wikipedia, instead it targets", rh.baseURL, rh.title2Query("Anarchisme", 1))
+	case rh.headers.Get("X-Base") != "1" || rh.headers.Get("X-Derived") != "1":
+		t.Error("Derive should add to the inherited headers, instead they are", rh.headers)
+	case base.headers.Get("X-Derived") != "" || base.lang != "en":
+		t.Error("Derive shouldn't affect the base handler")
+	}
+
+	client := &http.Client{}
+	if rh = base.Derive(WithHTTPClient(client)); rh.client != client || base.client == client {
+		t.Error("Options passed to Derive should replace the inherited configuration of the copy only")
+	}
+
+	base.Close()
+	if !rh.closer.Closed() {
+		t.Error("Derived handlers should be closed along with the base one")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+	}))
+	defer server.Close()
+	base = New("en", WithBaseURL(server.URL), WithMaxAttempts(1))
+	fr, de := base.Derive(WithLanguage("fr")), base.Derive(WithLanguage("de"), WithBaseURL(server.URL))
+	if fr.Close(); !fr.closer.Closed() {
+		t.Error("Derived handlers should be closable")
+	}
+	if _, err := base.From(context.Background(), "Anarchism"); err != nil {
+		t.Error("Closing a derived handler shouldn't close the base one, instead From returns", err)
+	}
+	if _, err := de.From(context.Background(), "Anarchism"); err != nil {
+		t.Error("Closing a derived handler shouldn't close its siblings, instead From returns", err)
+	}
+
+	base.Close()
+	if _, err := de.From(context.Background(), "Anarchism"); errors.Cause(err) != ErrClosed {
+		t.Error("Derived handlers should be closed along with the base one, instead From returns", err)
+	}
+}
+
+func TestWithQueryBuilder(t *testing.T) {
+	var lives []float64
+	rh := New("mytest", WithQueryBuilder(func(title string, life float64) string {
+		lives = append(lives, life)
+		return "http://" + address + "?pageids=" + title + "&signature=0123"
+	}))
+
+	expected, _ := generatePage(1)
+	expected.RequestedTitle, expected.NormalizedTitle = "1", "1"
+	for _, options := range [][]CallOption{nil, {ForceFallback()}, {WithLang("it"), Include(ExtraLength)}} {
+		ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+		p, err := rh.From(ctx, "1", options...)
+		cancel()
+		if !reflect.DeepEqual(p, expected) || err != nil {
+			t.Error("From", options, "through the custom builder returns", p, err)
+		}
+	}
+	if len(lives) < 3 || lives[0] != 1 {
+		t.Error("The custom builder was called with lives", lives)
+	}
+	for _, life := range lives {
+		if life == 0 {
+			return
+		}
+	}
+	t.Error("ForceFallback should call the custom builder with life 0, instead lives are", lives)
+}
+
+func TestForbidden(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Wikimedia Error</title><style>body { font: 14px sans-serif; }</style></head>`+
+			`<body><h1>Error</h1><p>Please set a user-agent and respect our robot policy https://w.wiki/4wJS. See also T400119 &amp; more.</p></body></html>`)
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	rh.title2Query = defaultTitle2Query(rh)
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+
+	_, err := rh.From(ctx, "Anarchism")
+	switch refusal, ok := errors.Cause(err).(Forbidden); {
+	case !ok:
+		t.Error("From should report the refusal, instead it returns", err)
+	case refusal.Reason != "Error Please set a user-agent and respect our robot policy https://w.wiki/4wJS. See also T400119 & more.":
+		t.Errorf("Forbidden reports the reason %q", refusal.Reason)
+	}
+	if requests != 1 {
+		t.Error("From shouldn't retry forbidden requests, instead it issued", requests, "requests")
+	}
+
+	if _, err = rh.SiteStats(ctx); !isForbidden(err) {
+		t.Error("SiteStats should report the refusal, instead it returns", err)
+	}
+	if _, err = rh.FullTextReader(ctx, "Anarchism"); !isForbidden(err) {
+		t.Error("FullTextReader should report the refusal, instead it returns", err)
+	}
+}
+
+func isForbidden(err error) bool {
+	_, ok := errors.Cause(err).(Forbidden)
+	return ok
+}
+
+func TestWithRetryOnEmptyExtract(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch title := r.URL.Query().Get("titles"); {
+		case r.URL.Path == "/api/rest_v1/page/summary/Anarchism":
+			fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"","description":"Political philosophy","lang":"en","dir":"ltr"}`)
+		case r.URL.Path == "/api/rest_v1/page/summary/Stub":
+			fmt.Fprint(w, `{"type":"standard","title":"Stub","pageid":13,"namespace":{"id":0},"extract":""}`)
+		case title == "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+		case title == "Stub":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":13,"ns":0,"title":"Stub","extract":""}]}}`)
+		default:
+			t.Error("Unexpected query", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+
+	rh := New("mytest", WithBaseURL(server.URL))
+	if p, err := rh.From(ctx, "Anarchism", Include(ExtraDescription)); err != nil || p.Abstract != "" || requests != 1 {
+		t.Errorf("By default From should accept the empty extract with a single request, instead it returns %+v, %v after %v requests", p, err, requests)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	rh = New("mytest", WithBaseURL(server.URL), WithRetryOnEmptyExtract())
+	switch p, err := rh.From(ctx, "Anarchism", Include(ExtraDescription)); {
+	case err != nil:
+		t.Error("From fails retrying the empty extract:", err)
+	case p.Abstract != "Anarchism is a political philosophy." || p.AbstractScope != "intro":
+		t.Errorf("From should take the abstract from the fall back API, instead it returns %+v", p)
+	case p.Description != "Political philosophy" || p.Lang != "en":
+		t.Errorf("From should keep the rest of the REST summary, instead it returns %+v", p)
+	case requests != 2:
+		t.Error("From should retry the empty extract once, instead it issued", requests, "requests")
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	if p, err := rh.From(ctx, "Stub"); err != nil || p.Abstract != "" || p.AbstractScope != "summary" || requests != 2 {
+		t.Errorf("From should accept the empty extract after retrying once, instead it returns %+v, %v after %v requests", p, err, requests)
+	}
+}
+
+// idleCloser counts the calls to CloseIdleConnections.
+type idleCloser struct {
+	http.RoundTripper
+	calls int
+}
+
+func (t *idleCloser) CloseIdleConnections() {
+	t.calls++
+}
+
+func TestSoftConnectionReset(t *testing.T) {
+	transport := &idleCloser{RoundTripper: http.DefaultTransport}
+	rh := New("mytest", WithHTTPClient(&http.Client{Transport: transport}))
+	title2Query := defaultTitle2Query(rh)
+	rh.include = ExtraLength
+	extrasQuery := defaultTitle2Query(rh)
+
+	for _, query := range []func() string{
+		func() string { return title2Query("Anarchism", 1) },
+		func() string { return title2Query("Anarchism", 0) }, //Forced fall back
+		func() string { return extrasQuery("Anarchism", 1) },
+	} {
+		if query(); transport.calls != 0 {
+			t.Fatal("Idle connections of the shared client should be kept unless retrying, instead they were closed by", query())
+		}
+	}
+	if title2Query("Anarchism", 0.1); transport.calls != 1 {
+		t.Error("Retries on the fall back API should reset idle connections, instead they were closed", transport.calls, "times")
+	}
+}
+
+func TestFromTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select { //Never reply
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer server.Close()
+	defer close(stop)
+
+	rh := New("mytest", WithBaseURL(server.URL))
+	start := time.Now()
+	_, err := rh.FromTimeout("Anarchism", 200*time.Millisecond)
+	if errors.Cause(err) != context.DeadlineExceeded {
+		t.Error("FromTimeout should fail with a deadline error, instead it returns", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 5*time.Second {
+		t.Error("FromTimeout should return after the timeout, instead it took", elapsed)
+	}
+}
+
+func TestTransientStatus(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusInternalServerError} {
+		var requests, failures int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) <= atomic.LoadInt32(&failures) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/unknown_error","title":"Error","method":"get","detail":"%v"}`, status)
+				return
+			}
+			fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+		}))
+
+		rh := New("mytest", WithBaseURL(server.URL), WithClock(&fakeClock{now: time.Now()}), WithMaxAttempts(2))
+		failures = 2
+		_, err := rh.From(context.Background(), "Anarchism")
+		if _, notFound := NotFound(err); notFound || err == nil {
+			t.Error("Status", status, "shouldn't be taken for a missing page, instead From returns", err)
+		}
+		if requests != 2 {
+			t.Error("Status", status, "should be retried, instead From issued", requests, "requests")
+		}
+		if p, err := rh.From(context.Background(), "Anarchism"); err != nil || p.ID != 12 || requests != 3 {
+			t.Error("Status", status, "shouldn't be cached, instead From returns", p, err, "after", requests, "requests")
+		}
+
+		atomic.StoreInt32(&requests, 0)
+		failures = 1
+		if p, err := rh.From(context.Background(), "Rome"); err != nil || p.ID != 12 || requests != 2 {
+			t.Error("Status", status, "should be retried, instead From returns", p, err, "after", requests, "requests")
+		}
+		server.Close()
+	}
+}
+
+func TestNotFoundConfirmed(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch title := r.URL.Query().Get("titles"); {
+		case r.URL.Path == "/api/rest_v1/page/summary/Missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found","title":"Not found."}`)
+		case title == "Missing":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Missing","missing":true}]}}`)
+		case r.URL.Path == "/api/rest_v1/page/summary/Empty":
+			fmt.Fprint(w, `{"type":"standard"}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest", WithBaseURL(server.URL), WithClock(&fakeClock{now: time.Now()}), WithMaxAttempts(2), WithNegativeCacheTTL(0))
+	for _, options := range [][]CallOption{nil, {ForceFallback()}} {
+		_, err := rh.From(context.Background(), "Missing", options...)
+		if details, ok := NotFoundDetailsOf(err); !ok || (details.Status != http.StatusOK && details.Status != http.StatusNotFound) {
+			t.Error("From", options, "should confirm the page as missing with status 200 or 404, instead it returns", details, err)
+		}
+
+		atomic.StoreInt32(&requests, 0)
+		_, err = rh.From(context.Background(), "Empty", options...)
+		if details, ok := NotFoundDetailsOf(err); ok || err == nil {
+			t.Error("From", options, "shouldn't take replies without a page for missing pages, instead it returns", details, err)
+		}
+		if requests != 2 {
+			t.Error("From", options, "should retry replies without a page, instead it issued", requests, "requests")
+		}
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	for _, reply := range []string{
+		`{"errors":[{"code":"maxlag","text":"Waiting for 10.64.16.8: 5 seconds lagged."}],"docref":"See https://en.wikipedia.org/w/api.php for API usage."}`,
+		`{"errors":[{"code":"readonly","text":"The wiki is currently in read-only mode."}]}`,
+		`{"error":{"code":"internal_api_error_DBQueryError","info":"A database query error has occurred."}}`,
+	} {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				fmt.Fprint(w, reply)
+				return
+			}
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","extract":"Anarchism is a political philosophy."}]}}`)
+		}))
+
+		rh := New("mytest", WithBaseURL(server.URL), WithClock(&fakeClock{now: time.Now()}), WithMaxAttempts(1))
+		_, err := rh.From(context.Background(), "Anarchism", ForceFallback())
+		if _, notFound := NotFound(err); notFound || err == nil {
+			t.Error("API errors shouldn't be taken for missing pages, instead From returns", err, "for", reply)
+		}
+		if p, err := rh.From(context.Background(), "Anarchism", ForceFallback()); err != nil || p.ID != 12 {
+			t.Error("API errors shouldn't be cached, instead From returns", p, err, "after", reply)
+		}
+
+		atomic.StoreInt32(&requests, 0)
+		rh = New("mytest", WithBaseURL(server.URL), WithClock(&fakeClock{now: time.Now()}), WithMaxAttempts(2))
+		if p, err := rh.From(context.Background(), "Anarchism", ForceFallback()); err != nil || p.ID != 12 || requests != 2 {
+			t.Error("API errors should be retried, instead From returns", p, err, "after", requests, "requests for", reply)
+		}
+		server.Close()
+	}
+}
+
+func TestDeriveNegativeCache(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get("signed") != "" || r.Header.Get("Accept-Language") != "" {
+			fmt.Fprint(w, `{"type":"standard","title":"Anarchism","pageid":12,"namespace":{"id":0},"extract":"Anarchism is a political philosophy."}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"type":"https://mediawiki.org/wiki/HyperSwitch/errors/not_found","title":"Not found."}`)
+	}))
+	defer server.Close()
+
+	base := New("mytest", WithBaseURL(server.URL))
+	if _, err := base.From(context.Background(), "Anarchism"); err == nil {
+		t.Fatal("From should fail on a missing page")
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	if _, err := base.Derive(WithHeader("X-Derived", "1")).From(context.Background(), "Anarchism"); err == nil || requests != 0 {
+		t.Error("Handlers querying alike should share missing pages, instead From returns", err, "after", requests, "requests")
+	}
+	for name, option := range map[string]Option{
+		"WithQueryBuilder": WithQueryBuilder(func(title string, life float64) string {
+			return server.URL + "/api/rest_v1/page/summary/" + title + "?signed=1"
+		}),
+		"WithVariant": WithVariant("zh-hans"),
+	} {
+		if p, err := base.Derive(option).From(context.Background(), "Anarchism"); err != nil || p.ID != 12 {
+			t.Error("Handlers derived through", name, "shouldn't share the missing pages of the base one, instead From returns", p, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package wikipagetest provides a fake wiki, to test code built on package wikipage without reaching Wikipedia.
+package wikipagetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/negapedia/wikipage"
+)
+
+// NewTestServer returns a started server faking the REST summary API and the query action API of a wiki with the specified pages,
+// keyed by title; point a RequestHandler at it through wikipage.WithBaseURL, and close it when done.
+// Titles are normalized as the wikis do, underscores as spaces and, unless CaseSensitive, the first letter upper case. A key different from the Title of its page
+// is a redirect to it: the REST API replies with an HTTP redirect, the action API reports it in the reply. Pages are served as they are,
+// so their Abstract is used both as the REST summary and as the introduction extract, and extras are served whatever is requested.
+// Queries of other actions, lists and meta information, apart from the general siteinfo, fail with an API error.
+func NewTestServer(pages map[string]wikipage.WikiPage, options ...Option) *httptest.Server {
+	s := server{pages: map[string]wikipage.WikiPage{}, IDs: map[wikipage.PageID]wikipage.WikiPage{}}
+	for _, option := range options {
+		option(&s)
+	}
+	for title, p := range pages {
+		s.pages[s.normalize(title)] = p
+		s.pages[s.normalize(p.Title)] = p
+		s.IDs[p.ID] = p
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/rest_v1/page/summary/", s.summary)
+	mux.HandleFunc("/w/api.php", s.query)
+	return httptest.NewServer(mux)
+}
+
+// Option customizes the fake wiki of NewTestServer.
+type Option func(s *server)
+
+// CaseSensitive makes the fake wiki keep the case of the first letter of titles, as Wiktionary does: e.g. "iPhone" and "IPhone" are different pages.
+func CaseSensitive() Option {
+	return func(s *server) {
+		s.caseSensitive = true
+	}
+}
+
+type server struct {
+	pages         map[string]wikipage.WikiPage
+	IDs           map[wikipage.PageID]wikipage.WikiPage
+	caseSensitive bool
+}
+
+// summary serves the REST API page summaries.
+func (s server) summary(w http.ResponseWriter, r *http.Request) {
+	title := s.normalize(strings.TrimPrefix(r.URL.Path, "/api/rest_v1/page/summary/"))
+	p, ok := s.pages[title]
+	switch {
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]string{"type": "https://mediawiki.org/wiki/HyperSwitch/errors/not_found", "title": "Not found.", "detail": "Page or revision not found."})
+		return
+	case s.normalize(p.Title) != title:
+		http.Redirect(w, r, "/api/rest_v1/page/summary/"+url.PathEscape(strings.Replace(p.Title, " ", "_", -1))+"?"+r.URL.RawQuery, http.StatusFound)
+		return
+	}
+
+	summary := restSummary{
+		Type: "standard", ID: p.ID, Title: p.Title, DisplayTitle: p.DisplayTitle, Extract: p.Abstract, ExtractHTML: p.AbstractHTML,
+		Lang: p.Lang, Dir: p.Dir, Description: p.Description, DescriptionSource: p.DescriptionSource, WikibaseItem: p.WikibaseItem,
+	}
+	summary.Namespace.ID = p.Namespace
+	summary.Titles.Display = p.DisplayTitle
+	if p.Thumbnail.Source != "" {
+		summary.Thumbnail = &p.Thumbnail
+	}
+	if p.Coordinates != (wikipage.Coordinates{}) {
+		summary.Coordinates = &p.Coordinates
+	}
+	writeJSON(w, summary)
+}
+
+// restSummary is the page summary object of the REST API.
+type restSummary struct {
+	Type         string          `json:"type"`
+	ID           wikipage.PageID `json:"pageid"`
+	Title        string          `json:"title"`
+	DisplayTitle string          `json:"displaytitle,omitempty"`
+	Titles       struct {
+		Display string `json:"display,omitempty"`
+	} `json:"titles"`
+	Namespace struct {
+		ID int `json:"id"`
+	} `json:"namespace"`
+	Extract           string                `json:"extract"`
+	ExtractHTML       string                `json:"extract_html,omitempty"`
+	Lang              string                `json:"lang,omitempty"`
+	Dir               string                `json:"dir,omitempty"`
+	Description       string                `json:"description,omitempty"`
+	DescriptionSource string                `json:"description_source,omitempty"`
+	Thumbnail         *wikipage.Image       `json:"thumbnail,omitempty"`
+	Coordinates       *wikipage.Coordinates `json:"coordinates,omitempty"`
+	WikibaseItem      string                `json:"wikibase_item,omitempty"`
+}
+
+// query serves the action API queries of pages by title or ID, and of the general siteinfo, in formatversion=2.
+func (s server) query(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("action") == "query" && q.Get("meta") == "siteinfo" && q.Get("siprop") == "general" {
+		titleCase := "first-letter"
+		if s.caseSensitive {
+			titleCase = "case-sensitive"
+		}
+		writeJSON(w, map[string]interface{}{"batchcomplete": true, "query": map[string]interface{}{"general": map[string]string{"sitename": "Test", "case": titleCase}}})
+		return
+	}
+	if q.Get("action") != "query" || q.Get("list") != "" || q.Get("meta") != "" || (q.Get("titles") == "") == (q.Get("pageids") == "") {
+		writeJSON(w, map[string]interface{}{"errors": []map[string]string{{"code": "badvalue", "text": "Unsupported by the test server."}}})
+		return
+	}
+
+	var reply queryReply
+	reply.BatchComplete = true
+	if titles := q.Get("titles"); titles != "" {
+		for _, title := range strings.Split(titles, "|") {
+			normalized := s.normalize(title)
+			if normalized != title {
+				reply.Query.Normalized = append(reply.Query.Normalized, hop{title, normalized})
+			}
+			p, ok := s.pages[normalized]
+			if !ok {
+				reply.Query.Pages = append(reply.Query.Pages, queryPage{Title: normalized, Missing: true})
+				continue
+			}
+			if s.normalize(p.Title) != normalized {
+				reply.Query.Redirects = append(reply.Query.Redirects, hop{normalized, p.Title})
+			}
+			reply.Query.Pages = append(reply.Query.Pages, newQueryPage(p))
+		}
+	} else {
+		for _, field := range strings.Split(q.Get("pageids"), "|") {
+			ID, err := strconv.ParseUint(field, 10, 32)
+			p, ok := s.IDs[wikipage.PageID(ID)]
+			if err != nil || !ok {
+				reply.Query.Pages = append(reply.Query.Pages, queryPage{ID: wikipage.PageID(ID), Missing: true})
+				continue
+			}
+			reply.Query.Pages = append(reply.Query.Pages, newQueryPage(p))
+		}
+	}
+	writeJSON(w, reply)
+}
+
+// queryReply is the reply of the action API to a query of pages.
+type queryReply struct {
+	BatchComplete bool `json:"batchcomplete"`
+	Query         struct {
+		Normalized []hop       `json:"normalized,omitempty"`
+		Redirects  []hop       `json:"redirects,omitempty"`
+		Pages      []queryPage `json:"pages"`
+	} `json:"query"`
+}
+
+type hop struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// queryPage is a page of the reply of the action API.
+type queryPage struct {
+	ID          wikipage.PageID        `json:"pageid,omitempty"`
+	Namespace   int                    `json:"ns"`
+	Title       string                 `json:"title"`
+	Missing     bool                   `json:"missing,omitempty"`
+	Extract     string                 `json:"extract,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Thumbnail   *wikipage.Image        `json:"thumbnail,omitempty"`
+	Coordinates []wikipage.Coordinates `json:"coordinates,omitempty"`
+	Length      uint32                 `json:"length,omitempty"`
+	PageProps   map[string]string      `json:"pageprops,omitempty"`
+}
+
+func newQueryPage(p wikipage.WikiPage) queryPage {
+	qp := queryPage{ID: p.ID, Namespace: p.Namespace, Title: p.Title, Extract: p.Abstract, Description: p.Description, Length: p.Length, PageProps: p.PageProps}
+	if p.Thumbnail.Source != "" {
+		qp.Thumbnail = &p.Thumbnail
+	}
+	if p.Coordinates != (wikipage.Coordinates{}) {
+		qp.Coordinates = []wikipage.Coordinates{p.Coordinates}
+	}
+	if p.WikibaseItem != "" && qp.PageProps["wikibase_item"] == "" {
+		qp.PageProps = map[string]string{"wikibase_item": p.WikibaseItem}
+		for name, value := range p.PageProps {
+			qp.PageProps[name] = value
+		}
+	}
+	return qp
+}
+
+// normalize normalizes title as the wikis do: underscores as spaces and, unless the wiki is case-sensitive, the first letter upper case.
+func (s server) normalize(title string) string {
+	title = strings.TrimSpace(strings.Replace(title, "_", " ", -1))
+	if title == "" || s.caseSensitive {
+		return title
+	}
+	r, size := utf8.DecodeRuneInString(title)
+	return string(unicode.ToUpper(r)) + title[size:]
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
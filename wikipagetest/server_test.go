@@ -0,0 +1,91 @@
+package wikipagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/negapedia/wikipage"
+)
+
+var fixtures = map[string]wikipage.WikiPage{
+	"Anarchism": {ID: 12, Title: "Anarchism", Abstract: "Anarchism is a political philosophy.", Lang: "en", Dir: "ltr", WikibaseItem: "Q6199"},
+	"Anarchy":   {ID: 12, Title: "Anarchism", Abstract: "Anarchism is a political philosophy.", Lang: "en", Dir: "ltr", WikibaseItem: "Q6199"},
+	"Rome":      {ID: 25458, Title: "Rome", Abstract: "Rome is the capital city of Italy.", Lang: "en", Dir: "ltr", Coordinates: wikipage.Coordinates{Lat: 41.89, Lon: 12.48}},
+}
+
+func TestNewTestServer(t *testing.T) {
+	server := NewTestServer(fixtures)
+	defer server.Close()
+	rh := wikipage.New("en", wikipage.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	for _, options := range [][]wikipage.CallOption{nil, {wikipage.ForceFallback()}} {
+		for title, expected := range map[string]string{"Anarchism": "Anarchism", "anarchy": "Anarchism", "Rome": "Rome"} {
+			switch p, err := rh.From(ctx, title, options...); {
+			case err != nil:
+				t.Error("From", title, options, "returns", err)
+			case p.Title != expected || p.Abstract != fixtures[expected].Abstract || p.ID != fixtures[expected].ID:
+				t.Error("From", title, options, "returns", p, "expected", fixtures[expected])
+			}
+		}
+		if _, err := rh.From(ctx, "Missing", options...); err == nil {
+			t.Error("From", options, "should fail on a missing page")
+		} else if _, ok := wikipage.NotFound(err); !ok {
+			t.Error("From", options, "returns", err, "expected not found")
+		}
+	}
+
+	p, err := rh.From(ctx, "Rome", wikipage.Include(wikipage.ExtraCoordinates))
+	if err != nil || p.Coordinates != fixtures["Rome"].Coordinates {
+		t.Error("From returns", p.Coordinates, err, "expected", fixtures["Rome"].Coordinates)
+	}
+
+	ID2Page, ID2Error := rh.FromIDs(ctx, []wikipage.PageID{12, 25458, 1})
+	if ID2Page[12].Title != "Anarchism" || ID2Page[25458].Title != "Rome" {
+		t.Error("FromIDs returns", ID2Page)
+	}
+	if _, ok := wikipage.NotFound(ID2Error[1]); !ok {
+		t.Error("FromIDs returns", ID2Error[1], "expected not found")
+	}
+
+	title2Page, title2Error := rh.FromTitles(ctx, []string{"Anarchy", "Rome", "Missing"})
+	if title2Page["Anarchy"].Title != "Anarchism" || title2Page["Rome"].Title != "Rome" {
+		t.Error("FromTitles returns", title2Page)
+	}
+	if _, ok := wikipage.NotFound(title2Error["Missing"]); !ok {
+		t.Error("FromTitles returns", title2Error["Missing"], "expected not found")
+	}
+
+	if _, err := rh.SiteStats(ctx); err == nil {
+		t.Error("Unsupported queries should fail")
+	}
+}
+
+func TestCaseSensitive(t *testing.T) {
+	pages := map[string]wikipage.WikiPage{
+		"iPhone": {ID: 1, Title: "iPhone", Abstract: "The iPhone is a smartphone."},
+		"IPhone": {ID: 2, Title: "IPhone", Abstract: "IPhone is a misspelling."},
+	}
+	server := NewTestServer(pages, CaseSensitive())
+	defer server.Close()
+	ctx := context.Background()
+
+	rh := wikipage.New("en", wikipage.WithBaseURL(server.URL), wikipage.WithStrictTitleMatch(), wikipage.WithCaseSensitiveTitles())
+	if caseSensitive, err := rh.CaseSensitiveTitles(ctx); err != nil || !caseSensitive {
+		t.Error("CaseSensitiveTitles returns", caseSensitive, err)
+	}
+	for _, options := range [][]wikipage.CallOption{nil, {wikipage.ForceFallback()}} {
+		for title, expected := range pages {
+			if p, err := rh.From(ctx, title, options...); err != nil || p.ID != expected.ID || p.Title != expected.Title {
+				t.Error("From", title, options, "returns", p, err, "expected", expected)
+			}
+		}
+	}
+
+	server = NewTestServer(pages)
+	defer server.Close()
+	rh = wikipage.New("en", wikipage.WithBaseURL(server.URL))
+	if caseSensitive, err := rh.CaseSensitiveTitles(ctx); err != nil || caseSensitive {
+		t.Error("CaseSensitiveTitles returns", caseSensitive, err)
+	}
+}
@@ -0,0 +1,53 @@
+package wikipage
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Wikitext returns the wikitext source of the latest revision of the article with the specified title, following redirects.
+func (rh RequestHandler) Wikitext(ctx context.Context, title string) (wikitext string, err error) {
+	rh = rh.fullContent()
+	query := rh.apiQuery(url.Values{
+		"action":    {"query"},
+		"prop":      {"revisions"},
+		"rvprop":    {"content"},
+		"rvslots":   {"main"},
+		"redirects": {""},
+		"titles":    {title},
+	})
+
+	var data struct {
+		Query struct {
+			Pages []struct {
+				Missing   bool
+				Revisions []struct {
+					Slots struct {
+						Main struct {
+							Content string
+							Star    string `json:"*"` //Content as reported by formatversion=1
+						}
+					}
+				}
+			}
+		}
+		apiErrors
+	}
+	if err = rh.getJSON(ctx, query, &data); err != nil {
+		return
+	}
+	if err = data.asError(title); err != nil {
+		return
+	}
+
+	if len(data.Query.Pages) == 0 || data.Query.Pages[0].Missing || len(data.Query.Pages[0].Revisions) == 0 {
+		return "", errors.WithStack(pageNotFound{title: title, endpoint: "query"})
+	}
+	main := data.Query.Pages[0].Revisions[0].Slots.Main
+	if main.Content == "" {
+		main.Content = main.Star
+	}
+	return main.Content, nil
+}
@@ -0,0 +1,32 @@
+package wikipage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWikitext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("titles") {
+		case "Anarchism":
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"pageid":12,"ns":0,"title":"Anarchism","revisions":[{"slots":{"main":{"contentmodel":"wikitext","contentformat":"text/x-wiki","content":"{{Infobox}}\n'''Anarchism''' is a political philosophy."}}}]}]}}`)
+		default:
+			fmt.Fprint(w, `{"batchcomplete":true,"query":{"pages":[{"ns":0,"title":"Atlantis","missing":true}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	rh := New("mytest")
+	rh.baseURL = server.URL
+	if wikitext, err := rh.Wikitext(context.Background(), "Anarchism"); err != nil || wikitext != "{{Infobox}}\n'''Anarchism''' is a political philosophy." {
+		t.Error("Wikitext returns", wikitext, err)
+	}
+	if _, err := rh.Wikitext(context.Background(), "Atlantis"); err == nil {
+		t.Error("Wikitext should fail on a missing page")
+	} else if _, ok := NotFound(err); !ok {
+		t.Error("Wikitext returns", err, "expected not found")
+	}
+}